@@ -0,0 +1,71 @@
+package errors
+
+import (
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+// BuildInfo identifies the running binary, e.g. a version or commit hash, and
+// is recorded on every error when it is created. When empty, the version and
+// VCS revision from debug.ReadBuildInfo are used instead.
+var BuildInfo string
+
+var (
+	buildOnce   sync.Once
+	buildData   *debug.BuildInfo
+	buildString string
+)
+
+// readBuildInfo returns the (cached) build information of the binary, or nil
+// if it is not available.
+func readBuildInfo() *debug.BuildInfo {
+	buildOnce.Do(func() {
+		info, ok := debug.ReadBuildInfo()
+		if !ok {
+			return
+		}
+		buildData = info
+		buildString = info.Main.Version
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" {
+				buildString += " " + setting.Value
+			}
+		}
+	})
+	return buildData
+}
+
+func buildInfo() string {
+	if BuildInfo != "" {
+		return BuildInfo
+	}
+	readBuildInfo()
+	return buildString
+}
+
+// Build returns the build information recorded when the error was created.
+func (err *CommonError) Build() string {
+	return err.build
+}
+
+// Module returns the path and version of the module the frame's package
+// belongs to, according to the binary's build information. ok is false for
+// packages outside of any module, such as the standard library, or when the
+// binary carries no build information.
+func (frame *StackFrame) Module() (path, version string, ok bool) {
+	info := readBuildInfo()
+	if info == nil || frame.Package == "" {
+		return "", "", false
+	}
+	modules := append([]*debug.Module{&info.Main}, info.Deps...)
+	for _, module := range modules {
+		if module.Path == "" || len(module.Path) <= len(path) {
+			continue
+		}
+		if frame.Package == module.Path || strings.HasPrefix(frame.Package, module.Path+"/") {
+			path, version, ok = module.Path, module.Version, true
+		}
+	}
+	return path, version, ok
+}
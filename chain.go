@@ -0,0 +1,179 @@
+package errors
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// children returns the errors wrapped by err, for both the Unwrap() error
+// and the Unwrap() []error forms.
+func children(err error) []error {
+	switch u := err.(type) {
+	case interface{ Unwrap() []error }:
+		return u.Unwrap()
+	case interface{ Unwrap() error }:
+		if inner := u.Unwrap(); inner != nil {
+			return []error{inner}
+		}
+	}
+	return nil
+}
+
+// visited records the errors seen while walking a chain so that cycles can
+// be detected. Errors whose dynamic type is not comparable cannot be
+// recorded and are always reported as new.
+type visited map[error]bool
+
+func (v visited) add(err error) bool {
+	if !reflect.TypeOf(err).Comparable() {
+		return true
+	}
+	if v[err] {
+		return false
+	}
+	v[err] = true
+	return true
+}
+
+func (v visited) remove(err error) {
+	if reflect.TypeOf(err).Comparable() {
+		delete(v, err)
+	}
+}
+
+// Tree renders err and everything it wraps as an indented tree, one error per
+// line with its message and type. Errors wrapping several errors, like the
+// ones from the standard library's errors.Join, get one branch per member.
+// An error met again below itself is shown once more marked "(cycle)" and
+// not descended into. A nil *CommonError shows as "<nil>".
+func Tree(err error) string {
+	var buf bytes.Buffer
+	if err != nil {
+		writeTree(&buf, err, 0, visited{})
+	}
+	return buf.String()
+}
+
+func writeTree(buf *bytes.Buffer, err error, depth int, seen visited) {
+	indent := strings.Repeat("  ", depth)
+	if IsNil(err) {
+		fmt.Fprintf(buf, "%s<nil> (%T)\n", indent, err)
+		return
+	}
+	if !seen.add(err) {
+		fmt.Fprintf(buf, "%s%s (%T) (cycle)\n", indent, err.Error(), err)
+		return
+	}
+	fmt.Fprintf(buf, "%s%s (%T)\n", indent, err.Error(), err)
+	for _, child := range children(err) {
+		if child != nil {
+			writeTree(buf, child, depth+1, seen)
+		}
+	}
+	seen.remove(err)
+}
+
+// walk calls fn on err and everything it wraps, depth first, until fn
+// returns true, and reports whether it did. Errors wrapping several errors
+// are descended into member by member; cycles are not followed, and neither
+// is a nil *CommonError, which wraps nothing.
+func walk(err error, fn func(error) bool) bool {
+	return walkSeen(err, fn, visited{})
+}
+
+func walkSeen(err error, fn func(error) bool, seen visited) bool {
+	if err == nil || !seen.add(err) {
+		return false
+	}
+	if fn(err) {
+		return true
+	}
+	if IsNil(err) {
+		return false
+	}
+	for _, child := range children(err) {
+		if walkSeen(child, fn, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// ContainsType reports whether any error in the chain of err has the same
+// concrete type as sample, typically a zero value such as (*os.PathError)(nil)
+// or MyError{}. Pointer and value types are distinct: a MyError sample does
+// not match a *MyError in the chain.
+func ContainsType(err error, sample interface{}) bool {
+	if sample == nil {
+		return false
+	}
+	t := reflect.TypeOf(sample)
+	return walk(err, func(e error) bool {
+		return reflect.TypeOf(e) == t
+	})
+}
+
+// AsError returns the first error in the chain of err that implements the
+// Error interface, giving access to its stack without knowing its concrete
+// type. It reports false when there is no such error.
+func AsError(err error) (Error, bool) {
+	var found Error
+	walk(err, func(e error) bool {
+		if s, ok := e.(Error); ok && !IsNil(e) {
+			found = s
+			return true
+		}
+		return false
+	})
+	return found, found != nil
+}
+
+// root returns the last error in the chain of err, following UnwrapOnce
+// until it returns nil or the chain loops back on itself. A nil *CommonError
+// at the end of the chain is not counted, so that the root can be printed.
+func root(err error) error {
+	seen := visited{}
+	for err != nil && seen.add(err) {
+		inner := UnwrapOnce(err)
+		if IsNil(inner) {
+			break
+		}
+		err = inner
+	}
+	return err
+}
+
+// StackFramesOf returns the stackframes of the first error in the chain of
+// err that implements the Error interface and has any. It reports false
+// when no error in the chain carries a stack.
+func StackFramesOf(err error) ([]StackFrame, bool) {
+	var frames []StackFrame
+	walk(err, func(e error) bool {
+		if s, ok := e.(Error); ok && !IsNil(e) {
+			frames = s.StackFrames()
+		}
+		return len(frames) > 0
+	})
+	return frames, len(frames) > 0
+}
+
+// Find returns the first error in the chain of err that is assignable to T,
+// which may be a concrete error type or an interface, with the semantics of
+// the standard library's errors.As: errors with an As(interface{}) bool
+// method are asked too. Both Errors and the errors inside them are looked at.
+func Find[T any](err error) (T, bool) {
+	var found T
+	ok := walk(err, func(e error) bool {
+		if v, ok := e.(T); ok {
+			found = v
+			return true
+		}
+		if as, ok := e.(interface{ As(interface{}) bool }); ok && as.As(&found) {
+			return true
+		}
+		return false
+	})
+	return found, ok
+}
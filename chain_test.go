@@ -0,0 +1,15 @@
+package errors
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestTreeNilCommonError(t *testing.T) {
+	var nilErr *CommonError
+	err := fmt.Errorf("x: %w", nilErr)
+	want := fmt.Sprintf("%s (%T)\n  <nil> (*errors.CommonError)\n", err.Error(), err)
+	if got := Tree(err); got != want {
+		t.Errorf("Tree = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,124 @@
+package errors
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// OriginDepth is the number of top stackframes SameOrigin compares. Values
+// below 1 are treated as 1.
+var OriginDepth = 1
+
+// SameOrigin reports whether both errors were created at the same place, that
+// is whether their top OriginDepth stackframes point at the same function,
+// file and line. The rest of the stacks may differ, which makes this a coarser
+// grouping than comparing whole stacks. Errors without stackframes have no
+// known origin and never match.
+func SameOrigin(a, b *CommonError) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	fa, fb := a.StackFrames(), b.StackFrames()
+	if len(fa) == 0 || len(fb) == 0 {
+		return false
+	}
+	depth := OriginDepth
+	if depth < 1 {
+		depth = 1
+	}
+	for i := 0; i < depth; i++ {
+		if i == len(fa) || i == len(fb) {
+			return len(fa) == len(fb)
+		}
+		if !sameFrame(fa[i], fb[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func sameFrame(a, b StackFrame) bool {
+	return a.Package == b.Package && a.Name == b.Name && a.File == b.File && a.LineNumber == b.LineNumber
+}
+
+// ChainDiff walks the chains of a and b side by side and describes the first
+// level at which they differ in message, type or code, together with the last
+// level they had in common. It returns "" when both chains are the same.
+func ChainDiff(a, b error) string {
+	seenA, seenB := visited{}, visited{}
+	var common string
+	for level := 0; ; level++ {
+		if a != nil && !seenA.add(a) {
+			a = nil
+		}
+		if b != nil && !seenB.add(b) {
+			b = nil
+		}
+		if a == nil && b == nil {
+			return ""
+		}
+		diff := ""
+		switch {
+		case a == nil:
+			diff = fmt.Sprintf("a ends, b has %q (%T)", b.Error(), b)
+		case b == nil:
+			diff = fmt.Sprintf("b ends, a has %q (%T)", a.Error(), a)
+		case reflect.TypeOf(a) != reflect.TypeOf(b):
+			diff = fmt.Sprintf("type %T != %T", a, b)
+		case a.Error() != b.Error():
+			diff = fmt.Sprintf("message %q != %q", a.Error(), b.Error())
+		case codeOf(a) != codeOf(b):
+			diff = fmt.Sprintf("code %q != %q", codeOf(a), codeOf(b))
+		}
+		if diff != "" {
+			if common != "" {
+				diff += fmt.Sprintf(" (after level %d: %s)", level-1, common)
+			}
+			return fmt.Sprintf("level %d: %s", level, diff)
+		}
+		common = fmt.Sprintf("%q (%T)", a.Error(), a)
+		a, b = UnwrapOnce(a), UnwrapOnce(b)
+	}
+}
+
+// codeOf returns the code of err if it is a *CommonError.
+func codeOf(err error) string {
+	if e, ok := err.(*CommonError); ok {
+		return e.code
+	}
+	return ""
+}
+
+// CompareStacks scores how similar the stacks of a and b are, from 0 for
+// nothing in common to 1 for the same stack. The score is twice the length
+// of the longest common subsequence of frames, matched by function, file and
+// line, divided by the total number of frames. Two empty stacks score 1; an
+// empty and a non-empty one 0.
+func CompareStacks(a, b *CommonError) float64 {
+	var fa, fb []StackFrame
+	if a != nil {
+		fa = a.StackFrames()
+	}
+	if b != nil {
+		fb = b.StackFrames()
+	}
+	if len(fa)+len(fb) == 0 {
+		return 1
+	}
+	// lcs[j] holds the common subsequence length of the frames seen so far
+	// of a and the first j frames of b.
+	lcs := make([]int, len(fb)+1)
+	for i := range fa {
+		prev := 0
+		for j := range fb {
+			cur := lcs[j+1]
+			if sameFrame(fa[i], fb[j]) {
+				lcs[j+1] = prev + 1
+			} else if lcs[j] > lcs[j+1] {
+				lcs[j+1] = lcs[j]
+			}
+			prev = cur
+		}
+	}
+	return 2 * float64(lcs[len(fb)]) / float64(len(fa)+len(fb))
+}
@@ -0,0 +1,18 @@
+package errors
+
+import "testing"
+
+func TestSameOriginNonPositiveDepth(t *testing.T) {
+	defer func(depth int) { OriginDepth = depth }(OriginDepth)
+	a := New("a")
+	b := New("b")
+	for _, depth := range []int{0, -1} {
+		OriginDepth = depth
+		if SameOrigin(a, b) {
+			t.Errorf("OriginDepth %d: errors made on different lines have the same origin", depth)
+		}
+		if !SameOrigin(a, a) {
+			t.Errorf("OriginDepth %d: an error does not have its own origin", depth)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package errors
+
+import (
+	"context"
+	"time"
+)
+
+// ContextKey is the type of the context keys WrapContext looks up.
+type ContextKey string
+
+// The context keys WrapContext copies into the error's fields. The field
+// names are the key strings.
+const (
+	TraceIDKey   ContextKey = "trace_id"
+	RequestIDKey ContextKey = "request_id"
+)
+
+var contextKeys = []ContextKey{TraceIDKey, RequestIDKey}
+
+// WrapContext is like Wrap but also copies the values stored in ctx under
+// TraceIDKey and RequestIDKey into the error's fields. Keys missing from the
+// context are skipped.
+func WrapContext(ctx context.Context, e interface{}, skip int) *CommonError {
+	err := wrapSkip(e, skip)
+	if ctx == nil {
+		return err
+	}
+	for _, key := range contextKeys {
+		if v := ctx.Value(key); v != nil {
+			err = err.WithField(string(key), v)
+		}
+	}
+	return err
+}
+
+// WrapDeadline is like Wrap but also records the deadline of ctx, if it has
+// one, so that errors such as context.DeadlineExceeded can tell which
+// deadline was missed. Deadline returns it.
+func WrapDeadline(ctx context.Context, e interface{}, skip int) *CommonError {
+	err := wrapSkip(e, skip)
+	if ctx == nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		err = err.clone()
+		err.deadline, err.deadlineSet = deadline, true
+	}
+	return err
+}
+
+// Deadline returns the context deadline recorded by WrapDeadline. ok is false
+// when none was recorded.
+func (err *CommonError) Deadline() (deadline time.Time, ok bool) {
+	return err.deadline, err.deadlineSet
+}
@@ -0,0 +1,37 @@
+package errors
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+)
+
+// EncodeStack returns the raw program counters of the error's stack as
+// base64 encoded varints, which is much more compact than the resolved
+// frames. Program counters are only meaningful to the binary that captured
+// them: decode them with DecodeStack, and pass them to NewWithStack, in a
+// process running the very same build.
+func (err *CommonError) EncodeStack() string {
+	buf := make([]byte, 0, len(err.stack)*binary.MaxVarintLen64)
+	for _, pc := range err.stack {
+		buf = binary.AppendUvarint(buf, uint64(pc))
+	}
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+// DecodeStack decodes the program counters encoded by EncodeStack.
+func DecodeStack(s string) ([]uintptr, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, New(err)
+	}
+	var pcs []uintptr
+	for len(buf) > 0 {
+		pc, n := binary.Uvarint(buf)
+		if n <= 0 {
+			return nil, Errorf("errors: invalid stack encoding: %s", s)
+		}
+		pcs = append(pcs, uintptr(pc))
+		buf = buf[n:]
+	}
+	return pcs, nil
+}
@@ -0,0 +1,31 @@
+// Package errhttp connects the errors package to net/http, so that the
+// errors package itself does not have to import it.
+package errhttp
+
+import (
+	"net/http"
+
+	"github.com/monoculum/errors"
+)
+
+// RequestIDHeader is the request header WrapRequest takes the request ID
+// from.
+var RequestIDHeader = "X-Request-Id"
+
+// WrapRequest is like errors.Wrap but also attaches the method and path of r
+// as the http_method and http_path fields, and the RequestIDHeader value, if
+// any, as the request_id field. With a nil request it is plain errors.Wrap.
+func WrapRequest(r *http.Request, e interface{}, skip int) *errors.CommonError {
+	err := errors.Wrap(e, 1+skip)
+	if r == nil {
+		return err
+	}
+	err = err.WithField("http_method", r.Method)
+	if r.URL != nil {
+		err = err.WithField("http_path", r.URL.Path)
+	}
+	if id := r.Header.Get(RequestIDHeader); id != "" {
+		err = err.WithField(string(errors.RequestIDKey), id)
+	}
+	return err
+}
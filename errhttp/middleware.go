@@ -0,0 +1,107 @@
+package errhttp
+
+import (
+	"bufio"
+	"io"
+	"log"
+	"net"
+	"net/http"
+
+	"github.com/monoculum/errors"
+)
+
+// Logger is called by Middleware with every recovered panic. By default it
+// logs the ErrorStack with the standard logger.
+var Logger = func(r *http.Request, err *errors.CommonError) {
+	log.Printf("panic serving %s %s: %s", r.Method, r.URL, err.ErrorStack())
+}
+
+// ResponseBody is the body Middleware answers recovered panics with.
+var ResponseBody = "Internal Server Error\n"
+
+// Middleware recovers panics in next, passes them to Logger as Errors made
+// by errors.WrapPanic and answers with a 500 Internal Server Error and
+// ResponseBody. A panic with a *errors.CommonError, as from errors.MustWrap,
+// is passed on as is to keep its stack. When next already started the
+// response, by writing, flushing or hijacking it, it is left as is since its
+// status and headers cannot be changed anymore. Like net/http, Middleware
+// lets http.ErrAbortHandler through.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := &responseWriter{ResponseWriter: w}
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if v == http.ErrAbortHandler {
+				panic(v)
+			}
+			err, ok := v.(*errors.CommonError)
+			if !ok || err == nil {
+				err = errors.WrapPanic(v, 0)
+			}
+			Logger(r, err)
+			if rw.written {
+				return
+			}
+			h := w.Header()
+			h.Del("Content-Length")
+			h.Del("Content-Encoding")
+			h.Set("Content-Type", "text/plain; charset=utf-8")
+			h.Set("X-Content-Type-Options", "nosniff")
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(ResponseBody))
+		}()
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// responseWriter records whether the response was started. It implements
+// http.Flusher, http.Hijacker and io.ReaderFrom whatever the ResponseWriter
+// it wraps does, so that handlers checking for them keep working; when the
+// wrapped one lacks them, Flush does nothing and Hijack fails with
+// http.ErrNotSupported.
+type responseWriter struct {
+	http.ResponseWriter
+	written bool
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	w.written = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.written = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the original ResponseWriter for http.ResponseController.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// FlushError flushes the wrapped ResponseWriter. http.ResponseController
+// uses it in preference to Flush.
+func (w *responseWriter) FlushError() error {
+	w.written = true
+	return http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *responseWriter) Flush() {
+	w.FlushError()
+}
+
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, buf, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err == nil {
+		w.written = true
+	}
+	return conn, buf, err
+}
+
+func (w *responseWriter) ReadFrom(r io.Reader) (int64, error) {
+	w.written = true
+	return io.Copy(w.ResponseWriter, r)
+}
@@ -0,0 +1,88 @@
+package errhttp
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/monoculum/errors"
+)
+
+// serve runs h behind Middleware and returns the response and the error
+// passed to Logger.
+func serve(t *testing.T, h http.HandlerFunc) (*httptest.ResponseRecorder, *errors.CommonError) {
+	t.Helper()
+	defer func(l func(*http.Request, *errors.CommonError)) { Logger = l }(Logger)
+	var logged *errors.CommonError
+	Logger = func(r *http.Request, err *errors.CommonError) { logged = err }
+
+	rec := httptest.NewRecorder()
+	Middleware(h).ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	return rec, logged
+}
+
+func TestMiddlewareAnswersPanics(t *testing.T) {
+	rec, logged := serve(t, func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+	if rec.Code != http.StatusInternalServerError || rec.Body.String() != ResponseBody {
+		t.Errorf("response = %d %q, want 500 %q", rec.Code, rec.Body.String(), ResponseBody)
+	}
+	if logged == nil || !errors.IsPanic(logged) {
+		t.Errorf("logged %v, want a panic error", logged)
+	}
+}
+
+func TestMiddlewareKeepsCommonErrorPanics(t *testing.T) {
+	want := errors.New("setup failed")
+	_, logged := serve(t, func(w http.ResponseWriter, r *http.Request) {
+		panic(want)
+	})
+	if logged != want {
+		t.Errorf("logged %v, want the panic value itself", logged)
+	}
+}
+
+func TestMiddlewareForwardsFlusher(t *testing.T) {
+	rec, _ := serve(t, func(w http.ResponseWriter, r *http.Request) {
+		f, ok := w.(http.Flusher)
+		if !ok {
+			t.Fatal("ResponseWriter does not implement http.Flusher")
+		}
+		io.WriteString(w, "data: 1\n\n")
+		f.Flush()
+		panic("boom")
+	})
+	if !rec.Flushed {
+		t.Error("Flush did not reach the underlying ResponseWriter")
+	}
+	if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), ResponseBody) {
+		t.Errorf("response = %d %q, want the started response untouched", rec.Code, rec.Body.String())
+	}
+}
+
+func TestMiddlewareTracksResponseControllerFlush(t *testing.T) {
+	rec, _ := serve(t, func(w http.ResponseWriter, r *http.Request) {
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Fatal(err)
+		}
+		panic("boom")
+	})
+	if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), ResponseBody) {
+		t.Errorf("response = %d %q, want the flushed response untouched", rec.Code, rec.Body.String())
+	}
+}
+
+func TestMiddlewareHijackNotSupported(t *testing.T) {
+	serve(t, func(w http.ResponseWriter, r *http.Request) {
+		h, ok := w.(http.Hijacker)
+		if !ok {
+			t.Fatal("ResponseWriter does not implement http.Hijacker")
+		}
+		if _, _, err := h.Hijack(); err == nil {
+			t.Error("Hijack of a ResponseRecorder succeeded")
+		}
+	})
+}
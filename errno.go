@@ -0,0 +1,17 @@
+package errors
+
+import (
+	stderrors "errors"
+	"syscall"
+)
+
+// Errno returns the first syscall.Errno found in the chain of err, looking
+// through Errors as well as wrappers like *os.PathError, so that e.g. EACCES
+// and ENOENT can be told apart far from the failing call.
+func Errno(err error) (syscall.Errno, bool) {
+	var errno syscall.Errno
+	if stderrors.As(err, &errno) {
+		return errno, true
+	}
+	return 0, false
+}
@@ -6,20 +6,70 @@ package errors
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"reflect"
 	"runtime"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
 )
 
 // The maximum number of stackframes on any error.
 var MaxStackDepth = 50
 
+// OnEmptyStack, when set, is called with every error whose stack came out
+// empty, which usually means the skip passed to Wrap or one of its variants
+// went past the top of the stack.
+var OnEmptyStack func(err *CommonError)
+
+// DeepStackMaxFrames is the total number of frames DeepErrorStack shows.
+var DeepStackMaxFrames = 200
+
+// MaxPrefixLength, when positive, is the length in bytes beyond which
+// Error() cuts the prefixes short with "...". The wrapped error's own
+// message is never cut.
+var MaxPrefixLength = 0
+
+// VerboseErrorStack makes ErrorStack include the stacks of the errors
+// wrapped inside an Error, as FullErrorStack does.
+var VerboseErrorStack = false
+
+// PrefixSeparator is put between the prefixes added by WrapPrefix and the
+// error message.
+var PrefixSeparator = ": "
+
 // Error is an error with an attached stacktrace. It can be used
 // wherever the builtin error interface is expected.
 type CommonError struct {
-	Err    error
-	stack  []uintptr
-	frames []StackFrame
-	prefix string
+	Err         error
+	stack       []uintptr
+	frames      []StackFrame
+	prefixes    []string
+	truncated   bool
+	panicValue  interface{}
+	code        string
+	status      int
+	fields      map[string]interface{}
+	build       string
+	cause       error
+	skip        int
+	anchor      string
+	source      StackSource
+	annotations []string
+	severity    Severity
+	tags        []string
+	labels      map[string]string
+	userMessage string
+	wrapCount   int
+	timeout     bool
+	timeoutSet  bool
+	logged      bool
+	hint        string
+	message     string
+	rephrased   bool
+	deadline    time.Time
+	deadlineSet bool
 }
 
 type Error interface {
@@ -30,24 +80,62 @@ type Error interface {
 	TypeName() string
 }
 
+// callers returns the program counters of the current goroutine, starting at
+// the caller of the function that calls it plus skip frames. truncated is set
+// when the stack filled all MaxStackDepth slots and was likely cut off.
+func callers(skip int) (stack []uintptr, truncated bool) {
+	stack = stackCapturer(2+skip, MaxStackDepth)
+	return stack, MaxStackDepth > 0 && len(stack) == MaxStackDepth
+}
+
+// stackCapturer returns up to depth program counters of the current
+// goroutine, skip frames above its caller, like runtime.Callers. It is a
+// variable only so that tests can inject deterministic stacks; it must not
+// be changed outside of tests.
+var stackCapturer = func(skip, depth int) []uintptr {
+	stack := make([]uintptr, depth)
+	return stack[:runtime.Callers(2+skip, stack)]
+}
+
+// toError returns e if it is an error and fmt.Errorf("%v", e) otherwise.
+func toError(e interface{}) error {
+	if err, ok := e.(error); ok {
+		return err
+	}
+	return fmt.Errorf("%v", e)
+}
+
+// newError makes an Error around err with the stack starting at the caller
+// of the function calling newError, plus skip frames.
+func newError(err error, skip int, source StackSource) *CommonError {
+	stack, truncated := callers(1 + skip)
+	e := &CommonError{
+		Err:       err,
+		stack:     stack,
+		truncated: truncated,
+		build:     buildInfo(),
+		skip:      skip,
+		source:    source,
+	}
+	if len(stack) == 0 && MaxStackDepth > 0 && OnEmptyStack != nil {
+		OnEmptyStack(e)
+	}
+	return e
+}
+
+// SkipUsed returns the skip given to the function that captured the error's
+// stack, e.g. Wrap or WrapPrefix, and 0 for functions taking none, such as
+// New and Errorf. The frames those functions add themselves do not count.
+func (err *CommonError) SkipUsed() int {
+	return err.skip
+}
+
 // New makes an Error from the given value. If that value is already an
 // error then it will be used directly, if not, it will be passed to
 // fmt.Errorf("%v"). The stacktrace will point to the line of code that
 // called New.
 func New(e interface{}) *CommonError {
-	var err error
-	switch e := e.(type) {
-	case error:
-		err = e
-	default:
-		err = fmt.Errorf("%v", e)
-	}
-	stack := make([]uintptr, MaxStackDepth)
-	length := runtime.Callers(2, stack[:])
-	return &CommonError{
-		Err:   err,
-		stack: stack[:length],
-	}
+	return newError(toError(e), 0, SourceNew)
 }
 
 // Wrap makes an Error from the given value. If that value is already an
@@ -64,14 +152,129 @@ func Wrap(e interface{}, skip int) *CommonError {
 	default:
 		err = fmt.Errorf("%v", e)
 	}
-	stack := make([]uintptr, MaxStackDepth)
-	length := runtime.Callers(2+skip, stack[:])
+	return newError(err, skip, SourceWrap)
+}
+
+// wrapSkip is Wrap for the functions of this package that take a skip from
+// their own callers: the stack starts skip frames above the caller of the
+// function calling wrapSkip, and SkipUsed reports skip.
+func wrapSkip(e interface{}, skip int) *CommonError {
+	if err, ok := e.(*CommonError); ok {
+		return err
+	}
+	err := newError(toError(e), 1+skip, SourceWrap)
+	err.skip = skip
+	return err
+}
+
+// isNilValue reports whether e is nil or a nil *CommonError.
+func isNilValue(e interface{}) bool {
+	if e == nil {
+		return true
+	}
+	err, ok := e.(*CommonError)
+	return ok && err == nil
+}
+
+// WrapIf is like Wrap but returns nil when e is nil, so that it can be used
+// directly in early returns: return errors.WrapIf(err, 0). The result is a
+// typed *CommonError; returned through an error interface a nil result is
+// not == nil, so functions using it should return *CommonError or check the
+// value before converting it.
+func WrapIf(e interface{}, skip int) *CommonError {
+	if isNilValue(e) {
+		return nil
+	}
+	return wrapSkip(e, skip)
+}
+
+// MustWrap panics with Wrap(e, 0) unless e is nil, for setup code such as
+// init() where an error cannot be handled. The panic value is the
+// *CommonError, so recover handlers can get its stack back.
+func MustWrap(e interface{}) {
+	if !isNilValue(e) {
+		panic(wrapSkip(e, 0))
+	}
+}
+
+// IsNil reports whether err is nil, including the case of a nil *CommonError
+// stored in an error interface. Such an interface holds a type and so is not
+// == nil, which is easy to run into:
+//
+//	func find() error {
+//		var err *errors.CommonError // nil
+//		return err                  // find() != nil
+//	}
+func IsNil(err error) bool {
+	if err == nil {
+		return true
+	}
+	e, ok := err.(*CommonError)
+	return ok && e == nil
+}
+
+// Relayer makes an Error from the given value that records the stack of the
+// relay point, e.g. where an error crosses an API boundary. Unlike Wrap, an
+// existing *CommonError is not returned as is: it becomes the inner Err of the
+// new error, so both its original stack and the relay stack are kept. The skip
+// parameter indicates how far up the stack to start the relay stacktrace.
+// 0 is from the current call, 1 from its caller, etc.
+func Relayer(e interface{}, skip int) *CommonError {
+	return newError(toError(e), skip, SourceWrap)
+}
+
+// Here makes an Error with the given message whose stack only holds the
+// caller of Here. It is much cheaper than New when the location is all that
+// is needed, e.g. for logging.
+func Here(msg string) *CommonError {
 	return &CommonError{
-		Err:   err,
-		stack: stack[:length],
+		Err:    toError(msg),
+		stack:  stackCapturer(1, 1),
+		build:  buildInfo(),
+		source: SourceNew,
 	}
 }
 
+// NewFromAnchor makes an Error from the given value like New, with formatted
+// stacks ending just before anchorFunc: the anchor and the frames that called
+// it are left out, e.g. a web framework's dispatch function and the server
+// internals below it. anchorFunc is a full function name as for HideFunction.
+// If the anchor is not on the stack the whole stack is shown. StackFrames
+// always returns every frame.
+func NewFromAnchor(e interface{}, anchorFunc string) *CommonError {
+	err := newError(toError(e), 0, SourceNew)
+	err.anchor = anchorFunc
+	return err
+}
+
+// NewWithStack makes an Error from the given value like New, but uses the
+// given program counters as its stack instead of capturing one, e.g. from
+// runtime.Callers in another goroutine. Zero program counters are dropped
+// and the stack is capped at MaxStackDepth frames.
+func NewWithStack(e interface{}, pcs []uintptr) *CommonError {
+	stack := make([]uintptr, 0, len(pcs))
+	for _, pc := range pcs {
+		if pc != 0 {
+			stack = append(stack, pc)
+		}
+	}
+	truncated := false
+	if len(stack) > MaxStackDepth {
+		stack, truncated = stack[:MaxStackDepth], true
+	}
+	return &CommonError{
+		Err:       toError(e),
+		stack:     stack,
+		truncated: truncated,
+		build:     buildInfo(),
+		source:    SourceManual,
+	}
+}
+
+// Unwrap returns the error inside an Error, or e itself for any other
+// error. Unlike the standard library's errors.Unwrap it never returns nil for
+// a non-nil error, so it cannot be used to walk a chain; use UnwrapOnce for
+// that.
 func Unwrap(e error) error {
 	var err error
 	switch e := e.(type) {
@@ -85,69 +288,509 @@ func Unwrap(e error) error {
 	return err
 }
 
+// UnwrapOnce returns the error wrapped by err, or nil if err does not wrap a
+// single error, exactly like the standard library's errors.Unwrap.
+func UnwrapOnce(err error) error {
+	if u, ok := err.(interface{ Unwrap() error }); ok {
+		return u.Unwrap()
+	}
+	return nil
+}
+
+// UnwrapTo unwraps err depth times with UnwrapOnce and returns the error at
+// that level. It stops early at the root, the last error in the chain, and
+// when the chain loops back on itself.
+func UnwrapTo(err error, depth int) error {
+	seen := visited{}
+	for ; depth > 0 && err != nil && seen.add(err); depth-- {
+		inner := UnwrapOnce(err)
+		if inner == nil {
+			break
+		}
+		err = inner
+	}
+	return err
+}
+
 // WrapPrefix makes an Error from the given value. If that value is already an
 // error then it will be used directly, if not, it will be passed to
 // fmt.Errorf("%v"). The prefix parameter is used to add a prefix to the
 // error message when calling Error(). The skip parameter indicates how far
 // up the stack to start the stacktrace. 0 is from the current call,
 // 1 from its caller, etc.
+//
+// Like all the annotation methods WrapPrefix never modifies an existing
+// *CommonError; it returns an annotated copy instead, so that shared
+// sentinel errors stay untouched.
 func WrapPrefix(e interface{}, prefix string, skip int) *CommonError {
-	err := Wrap(e, skip)
-	if err.prefix != "" {
-		err.prefix = fmt.Sprintf("%s: %s", prefix, err.prefix)
+	return wrapSkip(e, skip).withPrefix(prefix)
+
+}
+
+// WrapPrefixf is like WrapPrefix with the prefix formatted as by
+// fmt.Sprintf: WrapPrefixf(err, 0, "user %d lookup", id).
+func WrapPrefixf(e interface{}, skip int, format string, a ...interface{}) *CommonError {
+	return wrapSkip(e, skip).withPrefix(fmt.Sprintf(format, a...))
+}
+
+// WrapPrefixDedup is like WrapPrefix but does not add prefix when it is
+// already the outermost prefix of the error, so that recursive code does not
+// produce messages like "foo: foo: message".
+func WrapPrefixDedup(e interface{}, prefix string, skip int) *CommonError {
+	return wrapSkip(e, skip).withPrefixDedup(prefix)
+}
+
+// WrapLazy is like WrapPrefix but takes the prefix from msgFn, which is only
+// called when e is not nil. It avoids building expensive context strings on
+// the happy path. Like WrapIf it returns nil when e is nil.
+func WrapLazy(e interface{}, skip int, msgFn func() string) *CommonError {
+	if isNilValue(e) {
+		return nil
+	}
+	return wrapSkip(e, skip).withPrefix(msgFn())
+}
+
+// withPrefixDedup is withPrefix unless prefix already is the outermost
+// prefix of err, in which case err is returned as is.
+func (err *CommonError) withPrefixDedup(prefix string) *CommonError {
+	if len(err.prefixes) > 0 && err.prefixes[0] == prefix {
+		return err
+	}
+	return err.withPrefix(prefix)
+}
+
+// withPrefix returns a copy of err with prefix in front of the existing
+// prefixes. Empty leading prefixes are dropped once another one is added, as
+// they never showed up in the message.
+func (err *CommonError) withPrefix(prefix string) *CommonError {
+	c := err.clone()
+	c.wrapCount++
+	if err.prefix() != "" {
+		c.prefixes = append([]string{prefix}, err.prefixes...)
 	} else {
-		err.prefix = prefix
+		c.prefixes = []string{prefix}
+	}
+	return c
+}
+
+// clone returns a copy of err that can be annotated without affecting err.
+func (err *CommonError) clone() *CommonError {
+	framesMu.RLock()
+	c := *err
+	framesMu.RUnlock()
+	c.prefixes = append([]string(nil), err.prefixes...)
+	c.annotations = append([]string(nil), err.annotations...)
+	c.tags = append([]string(nil), err.tags...)
+	if err.fields != nil {
+		c.fields = err.Fields()
+	}
+	if err.labels != nil {
+		c.labels = err.ProfileLabels()
 	}
+	return &c
+}
+
+// prefix returns the prefixes joined the way Error() shows them.
+func (err *CommonError) prefix() string {
+	return strings.Join(err.prefixes, PrefixSeparator)
+}
+
+// Annotatef returns a copy of the error with a formatted annotation appended
+// to its message, keeping the stack where it is instead of adding a layer
+// like WrapPrefix. Prefixes still come first: an error "open: no such file"
+// annotated with "retried %d times" renders as
+// "open: no such file: retried 3 times".
+func (err *CommonError) Annotatef(format string, a ...interface{}) *CommonError {
+	c := err.clone()
+	c.annotations = append(c.annotations, fmt.Sprintf(format, a...))
+	return c
+}
+
+// Rephrase makes an Error with newMessage in place of the message of e,
+// for when a prefix is not enough to put an error in context. An existing
+// *CommonError keeps its stack, metadata and so on, and any other value
+// gets a stack as with Wrap from the caller of Rephrase. Either way e becomes the inner Err, so Is
+// and the standard library's errors.Is still match what e matched; only
+// Error() changes, dropping the prefixes and annotations of e along with its
+// message. RootMessage still returns the message of the innermost error.
+func Rephrase(e interface{}, newMessage string) *CommonError {
+	err := wrapSkip(e, 0).clone()
+	err.Err = toError(e)
+	err.prefixes, err.annotations = nil, nil
+	err.message, err.rephrased = newMessage, true
 	return err
+}
+
+// WrapCount returns how many prefix layers WrapPrefix and its variants have
+// added to the error. High counts can point at errors wrapped over and over
+// on their way up.
+func (err *CommonError) WrapCount() int {
+	return err.wrapCount
+}
 
+// Prefixes returns the prefixes added to the error, outermost first. Error()
+// shows them joined by PrefixSeparator in front of the message.
+func (err *CommonError) Prefixes() []string {
+	return append([]string(nil), err.prefixes...)
+}
+
+// HasPrefix reports whether p is one of the prefixes added to the error by
+// WrapPrefix and its variants.
+func (err *CommonError) HasPrefix(p string) bool {
+	return containsString(err.prefixes, p)
+}
+
+// Unwrap returns the underlying error, so that the standard library's
+// errors.Is and errors.As can look through an Error. A nil *CommonError
+// wraps nothing.
+func (err *CommonError) Unwrap() error {
+	if err == nil {
+		return nil
+	}
+	return err.Err
 }
 
 // Is detects whether the error is equal to a given error. Errors
 // are considered equal by this function if they are the same object,
 // or if they both contain the same error inside an errors.Error.
+// Errors that wrap several errors, like the ones from the standard
+// library's errors.Join, are equal to the given error if any of their
+// members is.
 func Is(e error, original error) bool {
-	if e == original {
+	return is(e, original, make(map[[2]error]bool))
+}
+
+// is implements Is, recording the pairs of errors it compared in seen so that
+// errors wrapping themselves do not make it loop forever.
+func is(e error, original error, seen map[[2]error]bool) bool {
+	if isComparable(e) && e == original {
 		return true
 	}
-	if e, ok := e.(*CommonError); ok {
-		return Is(e.Err, original)
+	if isComparable(e) && isComparable(original) {
+		pair := [2]error{e, original}
+		if seen[pair] {
+			return false
+		}
+		seen[pair] = true
+	}
+	if e, ok := e.(*CommonError); ok && e != nil {
+		return is(e.Err, original, seen)
 	}
-	if original, ok := original.(*CommonError); ok {
-		return Is(e, original.Err)
+	if original, ok := original.(*CommonError); ok && original != nil {
+		return is(e, original.Err, seen)
+	}
+	if e, ok := e.(interface{ Unwrap() []error }); ok {
+		for _, member := range e.Unwrap() {
+			if member != nil && is(member, original, seen) {
+				return true
+			}
+		}
 	}
 	return false
 }
 
+// isComparable reports whether err can be compared with == and used as a map
+// key without panicking.
+func isComparable(err error) bool {
+	t := reflect.TypeOf(err)
+	return t == nil || t.Comparable()
+}
+
+// Equivalent reports whether a and b are equal in either direction, that is
+// Is(a, b) || Is(b, a). Unlike Is it is symmetric, for when it is not known
+// which of the two errors is the original.
+func Equivalent(a, b error) bool {
+	return Is(a, b) || Is(b, a)
+}
+
+// IsStrict is like Is but only unwraps e: each error in its chain is
+// compared to original as is, by identity or through the error's own Is
+// method, without looking inside original. So an Error made from a
+// sentinel matches the sentinel, while the sentinel does not match the
+// Error; Is finds both. The Is method of *CommonError is not used since it
+// looks inside original.
+func IsStrict(e error, original error) bool {
+	seen := visited{}
+	for ; e != nil && seen.add(e); e = UnwrapOnce(e) {
+		if reflect.TypeOf(e) == reflect.TypeOf(original) && reflect.TypeOf(e).Comparable() && e == original {
+			return true
+		}
+		if _, ok := e.(*CommonError); ok {
+			continue
+		}
+		if x, ok := e.(interface{ Is(error) bool }); ok && x.Is(original) {
+			return true
+		}
+	}
+	return false
+}
+
+// RootIs reports whether the root cause of e, the last error in its chain,
+// is original as Is sees it. Unlike Is, which matches at any level of the
+// chain, an error that only matches somewhere in the middle is not enough.
+func RootIs(e error, original error) bool {
+	return Is(root(e), original)
+}
+
+// Is reports whether target is a *CommonError around the same error as err,
+// so that the standard library's errors.Is treats two Errors made from the
+// same sentinel as equal. Only the Errors themselves are looked through:
+// the wrapped errors must be identical, not merely match each other.
+// Matching target against the wrapped error itself is left to errors.Is,
+// which goes on to Unwrap.
+func (err *CommonError) Is(target error) bool {
+	t, ok := target.(*CommonError)
+	if !ok || t == nil || err == nil {
+		return false
+	}
+	a, b := err.inner(), t.inner()
+	if a == nil || b == nil || reflect.TypeOf(a) != reflect.TypeOf(b) || !reflect.TypeOf(a).Comparable() {
+		return false
+	}
+	return a == b
+}
+
+// inner returns the error wrapped by err and any Errors directly inside it.
+func (err *CommonError) inner() error {
+	e := err.Err
+	for {
+		c, ok := e.(*CommonError)
+		if !ok || c == nil {
+			return e
+		}
+		e = c.Err
+	}
+}
+
 // Errorf creates a new error with the given message. You can use it
 // as a drop-in replacement for fmt.Errorf() to provide descriptive
 // errors in return values.
 func Errorf(format string, a ...interface{}) *CommonError {
-	return Wrap(fmt.Errorf(format, a...), 1)
+	err := wrapSkip(fmt.Errorf(format, a...), 0)
+	err.source = SourceErrorf
+	return err
 }
 
 // Error returns the underlying error's message.
 func (err *CommonError) Error() string {
 	msg := err.Err.Error()
-	if err.prefix != "" {
-		msg = fmt.Sprintf("%s: %s", err.prefix, msg)
+	if err.rephrased {
+		msg = err.message
+	}
+	for _, annotation := range err.annotations {
+		msg += PrefixSeparator + annotation
+	}
+	if prefix := err.prefix(); prefix != "" {
+		msg = truncatePrefix(prefix) + PrefixSeparator + msg
 	}
 	return msg
 }
 
+// truncatePrefix cuts prefix down to MaxPrefixLength bytes, on a rune
+// boundary, and marks the cut with "...".
+func truncatePrefix(prefix string) string {
+	if MaxPrefixLength <= 0 || len(prefix) <= MaxPrefixLength {
+		return prefix
+	}
+	cut := MaxPrefixLength
+	for cut > 0 && !utf8.RuneStart(prefix[cut]) {
+		cut--
+	}
+	return prefix[:cut] + "..."
+}
+
+// RootMessage returns the message of the innermost error in the chain,
+// without any of the prefixes or annotations added on the way, e.g. for
+// showing the root cause to end users.
+func (err *CommonError) RootMessage() string {
+	return root(err).Error()
+}
+
+// MarshalText implements encoding.TextMarshaler for text based loggers. It
+// returns the same message as Error().
+func (err *CommonError) MarshalText() ([]byte, error) {
+	return []byte(err.Error()), nil
+}
+
 // Stack returns the callstack formatted the same way that go does
 // in runtime/debug.Stack()
 func (err *CommonError) Stack() []byte {
 	var buf bytes.Buffer
-	for _, frame := range err.StackFrames() {
-		buf.WriteString(frame.String())
-	}
+	err.WriteStack(&buf)
 	return buf.Bytes()
 }
 
+// WriteStack writes the callstack as formatted by Stack to w, one frame at a
+// time, without building it in memory first. It returns the number of bytes
+// written and the first write error.
+func (err *CommonError) WriteStack(w io.Writer) (int, error) {
+	sw := &stickyWriter{w: w}
+	err.writeStack(sw)
+	return sw.n, sw.err
+}
+
+func (err *CommonError) writeStack(sw *stickyWriter) {
+	for _, run := range collapse(err.visibleFrames()) {
+		run.write(sw)
+	}
+	if err.truncated {
+		writeIndented(sw, fmt.Sprintf("... (stack truncated at %d frames) ...\n", len(err.stack)))
+	}
+}
+
+// CompactStack returns the callstack with one line per frame, as formatted
+// by StackFrame.Compact.
+func (err *CommonError) CompactStack() string {
+	var buf bytes.Buffer
+	sw := &stickyWriter{w: &buf}
+	for _, run := range collapse(err.visibleFrames()) {
+		line := run.frame.Compact()
+		if run.count > 1 {
+			line += fmt.Sprintf(" (repeated %d times)", run.count)
+		}
+		writeIndented(sw, line+"\n")
+	}
+	return buf.String()
+}
+
+// StackSignature returns the package and function of each frame that
+// formatted stacks show, one "package.function" per line from the top
+// frame down. Having no files or line numbers, it stays the same when code
+// moves around, which makes it fit for comparing against golden files.
+func (err *CommonError) StackSignature() string {
+	var buf strings.Builder
+	for _, frame := range err.visibleFrames() {
+		buf.WriteString(frame.fullName())
+		buf.WriteByte('\n')
+	}
+	return buf.String()
+}
+
+// PprofStack returns the callstack the way pprof and tools reading its text
+// output print it, the full function name of each frame followed by a line
+// with a tab and its file:line. The frames are those formatted stacks show,
+// but neither StackIndent nor CollapseRepeatedFrames is applied, so that the
+// output always parses.
+func (err *CommonError) PprofStack() string {
+	var buf strings.Builder
+	for _, frame := range err.visibleFrames() {
+		fmt.Fprintf(&buf, "%s\n\t%s:%d\n", frame.fullName(), frame.File, frame.LineNumber)
+	}
+	return buf.String()
+}
+
+// Location returns the file and line of the top stackframe as "file:line",
+// or "" if the error has no stackframes.
+func (err *CommonError) Location() string {
+	frames := err.StackFrames()
+	if len(frames) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", frames[0].File, frames[0].LineNumber)
+}
+
 // ErrorStack returns a string that contains both the
-// error message and the callstack.
+// error message and the callstack. With VerboseErrorStack set it
+// returns FullErrorStack instead.
 func (err *CommonError) ErrorStack() string {
-	return err.Error() + "\n" + string(err.Stack())
+	if VerboseErrorStack {
+		return err.FullErrorStack()
+	}
+	return err.plainErrorStack()
+}
+
+// FullErrorStack returns ErrorStack followed by the callstack of every
+// other error with a stack found while unwrapping, each introduced by the
+// message of its error.
+func (err *CommonError) FullErrorStack() string {
+	buf := bytes.NewBufferString(err.plainErrorStack())
+	seen := visited{}
+	seen.add(err)
+	for e := UnwrapOnce(err); e != nil && seen.add(e); e = UnwrapOnce(e) {
+		if s, ok := e.(interface{ Stack() []byte }); ok && !IsNil(e) {
+			fmt.Fprintf(buf, "--- wrapped: %s\n", e.Error())
+			buf.Write(s.Stack())
+		}
+	}
+	return buf.String()
+}
+
+// DeepErrorStack returns every layer of the error's chain, outermost first,
+// each as its message and type followed by its callstack if it has one,
+// formatted like Stack. Layers are separated by "---" lines. At most
+// DeepStackMaxFrames frames are shown in total, a run of repeated frames
+// counting as one; a loop in the chain ends the output.
+func (err *CommonError) DeepErrorStack() string {
+	var buf bytes.Buffer
+	sw := &stickyWriter{w: &buf}
+	budget := DeepStackMaxFrames
+	seen := visited{}
+	for e := error(err); e != nil && seen.add(e); e = UnwrapOnce(e) {
+		if e != error(err) {
+			sw.WriteString("---\n")
+		}
+		if IsNil(e) {
+			sw.WriteString(fmt.Sprintf("<nil> (%T)\n", e))
+			continue
+		}
+		sw.WriteString(fmt.Sprintf("%s (%T)\n", e.Error(), e))
+		s, ok := e.(Error)
+		if !ok {
+			continue
+		}
+		frames := s.StackFrames()
+		if c, ok := e.(*CommonError); ok {
+			frames = c.visibleFrames()
+		}
+		for _, run := range collapse(frames) {
+			if budget == 0 {
+				sw.WriteString("... (frame limit reached) ...\n")
+				return buf.String()
+			}
+			budget--
+			run.write(sw)
+		}
+	}
+	return buf.String()
+}
+
+func (err *CommonError) plainErrorStack() string {
+	var buf bytes.Buffer
+	err.writeErrorStack(&stickyWriter{w: &buf})
+	return buf.String()
+}
+
+func (err *CommonError) writeErrorStack(sw *stickyWriter) {
+	sw.WriteString(err.Error() + "\n")
+	if err.HasFrames() {
+		err.writeStack(sw)
+	} else {
+		sw.WriteString("(no stack available)\n")
+	}
+	if err.cause != nil {
+		sw.WriteString("caused by: " + errorStack(err.cause))
+	}
+}
+
+// errorStack returns e.ErrorStack() for errors that have one and the plain
+// message followed by a newline otherwise.
+func errorStack(e error) string {
+	if e, ok := e.(interface{ ErrorStack() string }); ok {
+		return e.ErrorStack()
+	}
+	return e.Error() + "\n"
+}
+
+// RelayStack returns a string that contains the error message, the
+// callstack and the callstacks of every relayed error found inside it,
+// innermost last.
+func (err *CommonError) RelayStack() string {
+	buf := bytes.NewBufferString(err.plainErrorStack())
+	for inner, ok := err.Err.(*CommonError); ok; inner, ok = inner.Err.(*CommonError) {
+		buf.WriteString("relayed from:\n")
+		buf.Write(inner.Stack())
+	}
+	return buf.String()
 }
 
 // TypeErrorStack returns a string that contains both the
@@ -157,21 +800,64 @@ func (err *CommonError) TypeErrorStack() string {
 }
 
 // StackFrames returns an array of frames containing information about the
-// stack.
+// stack. Calls to functions that were inlined get frames of their own.
 func (err *CommonError) StackFrames() []StackFrame {
+	framesMu.RLock()
+	frames := err.frames
+	framesMu.RUnlock()
+	if frames != nil {
+		return frames
+	}
+
+	frames = resolveFrames(err.stack)
+	framesMu.Lock()
+	defer framesMu.Unlock()
 	if err.frames == nil {
-		err.frames = make([]StackFrame, len(err.stack))
-		for i, pc := range err.stack {
-			err.frames[i] = NewStackFrame(pc)
-		}
+		err.frames = frames
 	}
 	return err.frames
 }
 
-// TypeName returns the type this error. e.g. *errors.stringError.
+// framesMu guards the frames StackFrames caches on errors, so that errors
+// shared between goroutines, like package level sentinels, can be formatted
+// and fingerprinted concurrently.
+var framesMu sync.RWMutex
+
+// HasFrames reports whether the error has any stackframes. Stacks can come
+// out empty, e.g. when a skip goes past the top of the stack; ErrorStack then
+// says "(no stack available)".
+func (err *CommonError) HasFrames() bool {
+	return len(err.StackFrames()) > 0
+}
+
+// DropStack returns a copy of the error without its stack, e.g. for errors
+// kept around for the life of the program where the stack only costs memory.
+// The copy's Stack is empty and HasFrames reports false.
+func (err *CommonError) DropStack() *CommonError {
+	c := err.clone()
+	c.stack, c.frames, c.truncated = nil, nil, false
+	return c
+}
+
+// IsTruncated reports whether the stack was cut off at MaxStackDepth frames.
+func (err *CommonError) IsTruncated() bool {
+	return err.truncated
+}
+
+// Source returns how the error's stack was captured: "new", "wrap",
+// "errorf", "panic", "manual" for stacks given to NewWithStack, or "unknown"
+// for errors built by hand.
+func (err *CommonError) Source() string {
+	return err.source.String()
+}
+
+// TypeName returns the type this error. e.g. *errors.stringError. Errors
+// directly inside the error, as left by Relayer and Rephrase, are looked
+// through, so the type is that of the error they wrap.
 func (err *CommonError) TypeName() string {
-	if _, ok := err.Err.(uncaughtPanic); ok {
+	inner := err.inner()
+	if _, ok := inner.(uncaughtPanic); ok {
 		return "panic"
 	}
-	return reflect.TypeOf(err.Err).String()
+	return reflect.TypeOf(inner).String()
 }
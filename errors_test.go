@@ -0,0 +1,190 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// findUser returns a nil *CommonError through an error, which makes the
+// returned error != nil.
+func findUser() error {
+	var err *CommonError
+	return err
+}
+
+func TestIsNilTypedNil(t *testing.T) {
+	err := findUser()
+	if err == nil {
+		t.Fatal("a nil *CommonError in an error interface is == nil")
+	}
+	if !IsNil(err) {
+		t.Error("IsNil does not see the nil *CommonError")
+	}
+	if !IsNil(nil) {
+		t.Error("IsNil(nil) = false")
+	}
+	if IsNil(New("x")) || IsNil(stderrors.New("x")) {
+		t.Error("IsNil reports a non-nil error as nil")
+	}
+}
+
+func TestIsJoinedMembers(t *testing.T) {
+	target := stderrors.New("target")
+	joined := stderrors.Join(stderrors.New("first"), target, stderrors.New("third"))
+
+	if !Is(joined, target) {
+		t.Error("Is does not find the second member of a Join")
+	}
+	if !Is(Wrap(joined, 0), target) {
+		t.Error("Is does not find the second member of a wrapped Join")
+	}
+	if Is(joined, stderrors.New("target")) {
+		t.Error("Is matches a different error with the same message")
+	}
+}
+
+func TestWrapPrefixSkip(t *testing.T) {
+	plain := stderrors.New("plain")
+	for name, err := range map[string]*CommonError{
+		"WrapPrefix":  WrapPrefix(plain, "p", 0),
+		"WrapPrefixf": WrapPrefixf(plain, 0, "p %d", 1),
+	} {
+		if top := err.StackFrames()[0].Name; top != "TestWrapPrefixSkip" {
+			t.Errorf("%s with skip 0: top frame is %s, want the caller", name, top)
+		}
+	}
+}
+
+func TestIsStrict(t *testing.T) {
+	sentinel := stderrors.New("not found")
+	wrapped := Wrap(sentinel, 0)
+
+	if !IsStrict(wrapped, sentinel) || !Is(wrapped, sentinel) {
+		t.Error("an Error made from the sentinel does not match the sentinel")
+	}
+	if IsStrict(sentinel, wrapped) {
+		t.Error("IsStrict looks inside the target")
+	}
+	if !Is(sentinel, wrapped) {
+		t.Error("Is does not look inside the target")
+	}
+	if IsStrict(Wrap(sentinel, 0), wrapped) {
+		t.Error("IsStrict matches a different Error around the same sentinel")
+	}
+	if !Is(Wrap(sentinel, 0), wrapped) {
+		t.Error("Is does not match two Errors around the same sentinel")
+	}
+	if !IsStrict(wrapped, wrapped) {
+		t.Error("IsStrict does not match an Error with itself")
+	}
+}
+
+func deepHelper(err error) *CommonError {
+	return Wrap(err, 0)
+}
+
+func recurse(n int) *CommonError {
+	if n == 0 {
+		return deepHelper(stderrors.New("deep"))
+	}
+	return recurse(n - 1)
+}
+
+func TestDeepErrorStackFormatsLikeStack(t *testing.T) {
+	HideFunction("github.com/monoculum/errors.deepHelper")
+	defer func(c bool) { CollapseRepeatedFrames = c }(CollapseRepeatedFrames)
+	CollapseRepeatedFrames = true
+
+	got := recurse(3).DeepErrorStack()
+	if strings.Contains(got, "\tdeepHelper:") {
+		t.Errorf("DeepErrorStack shows a hidden function:\n%s", got)
+	}
+	if !strings.Contains(got, "... (repeated 3 times) ...") {
+		t.Errorf("DeepErrorStack does not collapse repeated frames:\n%s", got)
+	}
+}
+
+func TestRootIs(t *testing.T) {
+	root := stderrors.New("root")
+	mid := fmt.Errorf("mid: %w", root)
+	err := WrapPrefix(mid, "top", 0)
+
+	if !RootIs(err, root) {
+		t.Error("RootIs does not match the root")
+	}
+	if !Is(err, mid) {
+		t.Error("Is does not match the middle of the chain")
+	}
+	if RootIs(err, mid) {
+		t.Error("RootIs matches the middle of the chain")
+	}
+	if !RootIs(root, root) {
+		t.Error("RootIs does not match an error that wraps nothing")
+	}
+}
+
+func TestTypeNameLooksThroughErrors(t *testing.T) {
+	panicked := WrapPanic("boom", 0)
+	if got := Rephrase(panicked, "request failed").TypeName(); got != "panic" {
+		t.Errorf("rephrased panic TypeName = %q, want %q", got, "panic")
+	}
+	if got := Relayer(New("x"), 0).TypeName(); got != "*errors.errorString" {
+		t.Errorf("relayed TypeName = %q, want %q", got, "*errors.errorString")
+	}
+	if Key(Rephrase(panicked, "request failed")) == Key(Rephrase(New("boom"), "request failed")) {
+		t.Error("Key does not tell a rephrased panic from a rephrased plain error")
+	}
+}
+
+func wrapWithSkip(err error) *CommonError {
+	return WrapPrefix(err, "helper", 1)
+}
+
+func TestSkipUsedIsCallerFacing(t *testing.T) {
+	plain := stderrors.New("plain")
+	for name, err := range map[string]*CommonError{
+		"New":        New("x"),
+		"Errorf":     Errorf("x"),
+		"Coded":      Coded("c", "x"),
+		"Wrap":       Wrap(plain, 0),
+		"WrapIf":     WrapIf(plain, 0),
+		"WrapPrefix": WrapPrefix(plain, "p", 0),
+		"WrapLazy":   WrapLazy(plain, 0, func() string { return "p" }),
+	} {
+		if got := err.SkipUsed(); got != 0 {
+			t.Errorf("%s: SkipUsed = %d, want 0", name, got)
+		}
+		if top := err.StackFrames()[0].Name; top != "TestSkipUsedIsCallerFacing" {
+			t.Errorf("%s: top frame is %s, want the caller", name, top)
+		}
+	}
+	err := wrapWithSkip(plain)
+	if got := err.SkipUsed(); got != 1 {
+		t.Errorf("SkipUsed through a helper = %d, want 1", got)
+	}
+	if top := err.StackFrames()[0].Name; top != "TestSkipUsedIsCallerFacing" {
+		t.Errorf("through a helper: top frame is %s, want the helper's caller", top)
+	}
+}
+
+func TestFullErrorStackNilCommonError(t *testing.T) {
+	var nilErr *CommonError
+	err := Wrap(fmt.Errorf("outer: %w", nilErr), 0)
+	if got := err.FullErrorStack(); strings.Contains(got, "--- wrapped") {
+		t.Errorf("FullErrorStack shows a nil *CommonError as wrapped:\n%s", got)
+	}
+
+	defer func(v bool) { VerboseErrorStack = v }(VerboseErrorStack)
+	VerboseErrorStack = true
+	err.ErrorStack()
+}
+
+func TestDeepErrorStackNilCommonError(t *testing.T) {
+	var nilErr *CommonError
+	got := Wrap(fmt.Errorf("outer: %w", nilErr), 0).DeepErrorStack()
+	if !strings.HasSuffix(got, "---\n<nil> (*errors.CommonError)\n") {
+		t.Errorf("DeepErrorStack does not end with the nil *CommonError:\n%s", got)
+	}
+}
@@ -0,0 +1,35 @@
+// Package errorstest provides test helpers for the errors package, kept out
+// of it so that it does not import testing.
+package errorstest
+
+import (
+	"testing"
+
+	"github.com/monoculum/errors"
+)
+
+// AssertPanics calls fn and fails the test if it does not panic. The panic
+// is returned as an Error for further assertions: a *CommonError panic value
+// as is, anything else as made by errors.WrapPanic.
+func AssertPanics(t testing.TB, fn func()) *errors.CommonError {
+	t.Helper()
+	var err *errors.CommonError
+	func() {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+			if e, ok := r.(*errors.CommonError); ok {
+				err = e
+			} else {
+				err = errors.WrapPanic(r, 0)
+			}
+		}()
+		fn()
+	}()
+	if err == nil {
+		t.Fatal("errorstest: function did not panic")
+	}
+	return err
+}
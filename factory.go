@@ -0,0 +1,33 @@
+package errors
+
+import "fmt"
+
+// A Factory makes errors that all carry the same prefix, e.g. the name of
+// the library or component making them.
+type Factory struct {
+	prefix string
+}
+
+// NewFactory makes a Factory whose errors are prefixed with defaultPrefix.
+func NewFactory(defaultPrefix string) *Factory {
+	return &Factory{prefix: defaultPrefix}
+}
+
+// New is like the package level New, with the factory's prefix added.
+func (f *Factory) New(e interface{}) *CommonError {
+	return newError(toError(e), 0, SourceNew).withPrefix(f.prefix)
+}
+
+// Wrap is like the package level Wrap, with the factory's prefix added
+// unless it is already the outermost prefix, as for WrapPrefixDedup, so that
+// wrapping the factory's own errors does not repeat it.
+func (f *Factory) Wrap(e interface{}, skip int) *CommonError {
+	return wrapSkip(e, skip).withPrefixDedup(f.prefix)
+}
+
+// Errorf is like the package level Errorf, with the factory's prefix added.
+func (f *Factory) Errorf(format string, a ...interface{}) *CommonError {
+	err := wrapSkip(fmt.Errorf(format, a...), 0)
+	err.source = SourceErrorf
+	return err.withPrefix(f.prefix)
+}
@@ -0,0 +1,20 @@
+package errors
+
+import (
+	stderrors "errors"
+	"testing"
+)
+
+func TestFactoryWrapDoesNotRepeatPrefix(t *testing.T) {
+	f := NewFactory("lib")
+	if got := f.Wrap(f.New("x"), 0).Error(); got != "lib: x" {
+		t.Errorf("Wrap of a factory error = %q, want %q", got, "lib: x")
+	}
+	err := f.Wrap(stderrors.New("x"), 0)
+	if got := err.Error(); got != "lib: x" {
+		t.Errorf("Wrap = %q, want %q", got, "lib: x")
+	}
+	if top := err.StackFrames()[0].Name; top != "TestFactoryWrapDoesNotRepeatPrefix" {
+		t.Errorf("top frame is %s, want the caller of Wrap", top)
+	}
+}
@@ -0,0 +1,31 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// FatalOutput is where Fatal and Fatalf write the error.
+var FatalOutput io.Writer = os.Stderr
+
+// Fatal wraps e like Wrap, writes its ErrorStack to FatalOutput and exits the
+// program with status 1. It is an alternative to log.Fatal that always shows
+// where the error came from.
+func Fatal(e interface{}) {
+	fatal(wrapSkip(e, 0))
+}
+
+// Fatalf is like Fatal with an error made as by Errorf.
+func Fatalf(format string, a ...interface{}) {
+	fatal(wrapSkip(fmt.Errorf(format, a...), 0))
+}
+
+func fatal(err *CommonError) {
+	if VerboseErrorStack {
+		io.WriteString(FatalOutput, err.FullErrorStack())
+	} else {
+		err.writeErrorStack(&stickyWriter{w: FatalOutput})
+	}
+	os.Exit(1)
+}
@@ -0,0 +1,74 @@
+package errors
+
+import (
+	"fmt"
+	"hash/fnv"
+)
+
+// FingerprintDepth is the number of top stackframes that make up an error's
+// fingerprint.
+var FingerprintDepth = 5
+
+// Fingerprint returns a short hash of the function, file and line of the top
+// FingerprintDepth stackframes. Errors created at the same site through the
+// same recent calls share a fingerprint, whatever their message. Errors
+// without stackframes are fingerprinted by type and message instead.
+func (err *CommonError) Fingerprint() string {
+	h := fnv.New64a()
+	frames := err.StackFrames()
+	if len(frames) == 0 {
+		fmt.Fprintf(h, "%s\n%s\n", err.TypeName(), err.Error())
+	}
+	for i, frame := range frames {
+		if i == FingerprintDepth {
+			break
+		}
+		fmt.Fprintf(h, "%s\n%s:%d\n", frame.fullName(), frame.File, frame.LineNumber)
+	}
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// GroupByFingerprint buckets errs by the Fingerprint of the first
+// *CommonError in each chain, which gathers the errors coming from the same
+// site. Errors without one in their chain are bucketed by their message.
+func GroupByFingerprint(errs []error) map[string][]error {
+	groups := make(map[string][]error)
+	for _, err := range errs {
+		key := groupKey(err)
+		groups[key] = append(groups[key], err)
+	}
+	return groups
+}
+
+func groupKey(err error) string {
+	var key string
+	if walk(err, func(e error) bool {
+		if c, ok := e.(*CommonError); ok && c != nil {
+			key = c.Fingerprint()
+			return true
+		}
+		return false
+	}) {
+		return key
+	}
+	return message(err)
+}
+
+// Key returns a string identifying the logical error err is, for use as a
+// map key in dedup maps and caches. It is made of the type name, the first
+// code in the chain and the message. The stack is deliberately left out, so
+// that the same error made at different times or places gets the same key.
+func Key(err error) string {
+	if IsNil(err) {
+		return ""
+	}
+	typeName := fmt.Sprintf("%T", err)
+	if e, ok := err.(*CommonError); ok && e != nil {
+		typeName = e.TypeName()
+	}
+	code := ""
+	if codes := AllCodes(err); len(codes) > 0 {
+		code = codes[0]
+	}
+	return typeName + "\x00" + code + "\x00" + err.Error()
+}
@@ -0,0 +1,136 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// StackIndent is put at the start of every line of formatted stacks, to set
+// them apart from the message in multi-line log fields.
+var StackIndent = ""
+
+// ExcludeFiles leaves the frames whose file path matches any of the patterns
+// out of formatted stacks, e.g. generated *.pb.go files. There are no include
+// filters: a frame is shown unless ExcludeFiles, HideFunction or the anchor of
+// NewFromAnchor leaves it out. Set it before errors are formatted; it is not
+// guarded against concurrent changes. StackFrames always returns every frame.
+var ExcludeFiles []*regexp.Regexp
+
+// CollapseRepeatedFrames makes formatted stacks show runs of identical
+// consecutive frames, as left by recursion, once with the number of times
+// they were repeated. StackFrames always returns every frame.
+var CollapseRepeatedFrames = false
+
+var (
+	hiddenMu sync.RWMutex
+	hidden   = make(map[string]bool)
+)
+
+// HideFunction leaves the named function out of formatted stacks, as done by
+// Stack and ErrorStack, which is useful for helpers that wrap errors on
+// behalf of their callers. fullName is the package path followed by the
+// function name, e.g. "example.com/app/db.wrapDBError" or
+// "example.com/app/db.(*Conn).wrap". StackFrames always returns every frame.
+func HideFunction(fullName string) {
+	hiddenMu.Lock()
+	defer hiddenMu.Unlock()
+	hidden[fullName] = true
+}
+
+func isHidden(frame StackFrame) bool {
+	hiddenMu.RLock()
+	defer hiddenMu.RUnlock()
+	return hidden[frame.fullName()]
+}
+
+func isExcluded(frame StackFrame) bool {
+	for _, pattern := range ExcludeFiles {
+		if pattern.MatchString(frame.File) {
+			return true
+		}
+	}
+	return false
+}
+
+// fullName returns the package path and function name of the frame, as
+// accepted by HideFunction.
+func (frame *StackFrame) fullName() string {
+	return frame.Package + "." + frame.Name
+}
+
+// visibleFrames returns the stackframes that formatted stacks show.
+func (err *CommonError) visibleFrames() []StackFrame {
+	frames := err.StackFrames()
+	visible := make([]StackFrame, 0, len(frames))
+	for _, frame := range frames {
+		if err.anchor != "" && frame.fullName() == err.anchor {
+			return visible
+		}
+		if !isHidden(frame) && !isExcluded(frame) {
+			visible = append(visible, frame)
+		}
+	}
+	return visible
+}
+
+// writeIndented writes s to sw with StackIndent in front of each line.
+func writeIndented(sw *stickyWriter, s string) {
+	if StackIndent == "" {
+		sw.WriteString(s)
+		return
+	}
+	for _, line := range strings.SplitAfter(s, "\n") {
+		if line != "" {
+			sw.WriteString(StackIndent + line)
+		}
+	}
+}
+
+// stickyWriter counts the bytes written to w and keeps the first error,
+// dropping every write after it.
+type stickyWriter struct {
+	w   io.Writer
+	n   int
+	err error
+}
+
+func (sw *stickyWriter) WriteString(s string) {
+	if sw.err != nil {
+		return
+	}
+	n, err := io.WriteString(sw.w, s)
+	sw.n += n
+	sw.err = err
+}
+
+// frameRun is a frame repeated count times in a row.
+type frameRun struct {
+	frame StackFrame
+	count int
+}
+
+// write writes the frame of the run as formatted stacks show it, followed by
+// how often it was repeated if more than once.
+func (run frameRun) write(sw *stickyWriter) {
+	writeIndented(sw, run.frame.String())
+	if run.count > 1 {
+		writeIndented(sw, fmt.Sprintf("... (repeated %d times) ...\n", run.count))
+	}
+}
+
+// collapse groups frames into runs of identical frames when
+// CollapseRepeatedFrames is set, and into runs of one frame otherwise.
+func collapse(frames []StackFrame) []frameRun {
+	runs := make([]frameRun, 0, len(frames))
+	for _, frame := range frames {
+		if n := len(runs); CollapseRepeatedFrames && n > 0 && sameFrame(runs[n-1].frame, frame) {
+			runs[n-1].count++
+			continue
+		}
+		runs = append(runs, frameRun{frame: frame, count: 1})
+	}
+	return runs
+}
@@ -0,0 +1,112 @@
+package errors
+
+// gRPC status codes, as defined by google.golang.org/grpc/codes.
+const (
+	grpcCanceled           = 1
+	grpcUnknown            = 2
+	grpcInvalidArgument    = 3
+	grpcDeadlineExceeded   = 4
+	grpcNotFound           = 5
+	grpcAlreadyExists      = 6
+	grpcPermissionDenied   = 7
+	grpcResourceExhausted  = 8
+	grpcFailedPrecondition = 9
+	grpcAborted            = 10
+	grpcOutOfRange         = 11
+	grpcUnimplemented      = 12
+	grpcInternal           = 13
+	grpcUnavailable        = 14
+	grpcDataLoss           = 15
+	grpcUnauthenticated    = 16
+)
+
+// grpcCodes maps the codes set with WithCode to gRPC codes. They are the
+// snake case names of the gRPC codes.
+var grpcCodes = map[string]int{
+	"canceled":            grpcCanceled,
+	"unknown":             grpcUnknown,
+	"invalid_argument":    grpcInvalidArgument,
+	"deadline_exceeded":   grpcDeadlineExceeded,
+	"not_found":           grpcNotFound,
+	"already_exists":      grpcAlreadyExists,
+	"permission_denied":   grpcPermissionDenied,
+	"resource_exhausted":  grpcResourceExhausted,
+	"failed_precondition": grpcFailedPrecondition,
+	"aborted":             grpcAborted,
+	"out_of_range":        grpcOutOfRange,
+	"unimplemented":       grpcUnimplemented,
+	"internal":            grpcInternal,
+	"unavailable":         grpcUnavailable,
+	"data_loss":           grpcDataLoss,
+	"unauthenticated":     grpcUnauthenticated,
+}
+
+// grpcStatuses maps the HTTP statuses set with WithStatus to gRPC codes, the
+// same way grpc-gateway maps them the other way round.
+var grpcStatuses = map[int]int{
+	400: grpcInvalidArgument,
+	401: grpcUnauthenticated,
+	403: grpcPermissionDenied,
+	404: grpcNotFound,
+	409: grpcAlreadyExists,
+	412: grpcFailedPrecondition,
+	429: grpcResourceExhausted,
+	499: grpcCanceled,
+	500: grpcInternal,
+	501: grpcUnimplemented,
+	503: grpcUnavailable,
+	504: grpcDeadlineExceeded,
+}
+
+// GRPCCoder is implemented by errors that know their gRPC status code, like
+// *CommonError. GRPCStatus uses it to find the code of any error in a chain.
+type GRPCCoder interface {
+	GRPCCode() int
+}
+
+// GRPCCode returns the gRPC status code for the error, from the code and
+// status found in its chain as Metadata reports them. A code that is the
+// snake case name of a gRPC code, e.g. "not_found" or "invalid_argument",
+// maps to that code and wins over the status. Otherwise the HTTP status maps
+// to the matching code, e.g. 404 to NotFound and 503 to Unavailable. Errors
+// with neither, or with ones that have no gRPC equivalent, are Internal.
+func (err *CommonError) GRPCCode() int {
+	code, status, _, _ := Metadata(err)
+	if c, ok := grpcCodes[code]; ok {
+		return c
+	}
+	if c, ok := grpcStatuses[status]; ok {
+		return c
+	}
+	return grpcInternal
+}
+
+// GRPCStatus returns the gRPC status code and message to report for err,
+// so that interceptors can build a status without this package depending
+// on google.golang.org/grpc:
+//
+//	code, msg := errors.GRPCStatus(err)
+//	return status.Error(codes.Code(code), msg)
+//
+// The code comes from the first GRPCCoder in the chain of err, and is
+// Internal when there is none. The message is the user message set in the
+// chain, or err.Error() when there is none. GRPCStatus(nil) returns code 0,
+// which is OK, and "".
+func GRPCStatus(err error) (code int, message string) {
+	if IsNil(err) {
+		return 0, ""
+	}
+	code = grpcInternal
+	walk(err, func(e error) bool {
+		if c, ok := e.(GRPCCoder); ok && !IsNil(e) {
+			code = c.GRPCCode()
+			return true
+		}
+		return false
+	})
+	message, ok := userMessageOf(err)
+	if !ok {
+		message = err.Error()
+	}
+	return code, message
+}
@@ -0,0 +1,29 @@
+package errors
+
+// WithHint returns a copy of the error with a hint for the operator on how
+// to fix it, e.g. "check that the config file exists". The hint is kept out
+// of Error().
+func (err *CommonError) WithHint(hint string) *CommonError {
+	c := err.clone()
+	c.hint = hint
+	return c
+}
+
+// Hint returns the hint set with WithHint, or "" if none was set.
+func (err *CommonError) Hint() string {
+	return err.hint
+}
+
+// HintOf returns the first hint set on an Error in the chain of err,
+// outermost first, or "" if there is none.
+func HintOf(err error) string {
+	var hint string
+	walk(err, func(e error) bool {
+		if e, ok := e.(*CommonError); ok && e != nil && e.hint != "" {
+			hint = e.hint
+			return true
+		}
+		return false
+	})
+	return hint
+}
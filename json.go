@@ -0,0 +1,122 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// MarshalScrubber, when set, is applied by MarshalJSON, ToMap and ZapFields
+// to the message and the file paths of the stackframes before they are
+// encoded, e.g. to redact personal data.
+var MarshalScrubber func(string) string
+
+func scrub(s string) string {
+	if MarshalScrubber == nil {
+		return s
+	}
+	return MarshalScrubber(s)
+}
+
+type jsonFrame struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Function string `json:"function"`
+	Package  string `json:"package"`
+}
+
+type jsonError struct {
+	Message string                 `json:"message"`
+	Type    string                 `json:"type"`
+	Code    string                 `json:"code,omitempty"`
+	Status  int                    `json:"status,omitempty"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+	Build   string                 `json:"build,omitempty"`
+	Hint    string                 `json:"hint,omitempty"`
+	Stack   []jsonFrame            `json:"stack,omitempty"`
+	Cause   *jsonError             `json:"cause,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler. The error is encoded as an object
+// with its message, type, metadata, build information, hint and stackframes, and
+// the cause set by WithCause, encoded the same way, under "cause". Causes
+// that are not *CommonError only have a message and a type; a cause met
+// again further down is not encoded a second time.
+func (err *CommonError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(err.toJSON(visited{}))
+}
+
+func (err *CommonError) toJSON(seen visited) *jsonError {
+	seen.add(err)
+	frames := err.StackFrames()
+	out := &jsonError{
+		Message: scrub(err.Error()),
+		Type:    err.TypeName(),
+		Code:    err.code,
+		Status:  err.status,
+		Fields:  err.fields,
+		Build:   err.build,
+		Hint:    err.hint,
+		Stack:   make([]jsonFrame, len(frames)),
+	}
+	for i, frame := range frames {
+		out.Stack[i] = jsonFrame{
+			File:     scrub(frame.File),
+			Line:     frame.LineNumber,
+			Function: frame.Name,
+			Package:  frame.Package,
+		}
+	}
+	if cause := err.cause; cause != nil && seen.add(cause) {
+		if c, ok := cause.(*CommonError); ok && c != nil {
+			out.Cause = c.toJSON(seen)
+		} else {
+			out.Cause = &jsonError{Message: scrub(cause.Error()), Type: fmt.Sprintf("%T", cause)}
+		}
+	}
+	return out
+}
+
+// ToMap returns the error as a map for structured logging sinks. It always
+// holds "message", "type" and "stack", the latter a list of maps with the
+// file, line, function and package of each frame, and "prefix", "code",
+// "status", "severity", "fields", "build" and "hint" when they are set.
+// MarshalScrubber is applied as it is by MarshalJSON.
+func (err *CommonError) ToMap() map[string]interface{} {
+	frames := err.StackFrames()
+	stack := make([]map[string]interface{}, len(frames))
+	for i, frame := range frames {
+		stack[i] = map[string]interface{}{
+			"file":     scrub(frame.File),
+			"line":     frame.LineNumber,
+			"function": frame.Name,
+			"package":  frame.Package,
+		}
+	}
+	m := map[string]interface{}{
+		"message": scrub(err.Error()),
+		"type":    err.TypeName(),
+		"stack":   stack,
+	}
+	if prefix := err.prefix(); prefix != "" {
+		m["prefix"] = scrub(prefix)
+	}
+	if err.code != "" {
+		m["code"] = err.code
+	}
+	if err.status != 0 {
+		m["status"] = err.status
+	}
+	if err.severity != SeverityUnset {
+		m["severity"] = err.severity.String()
+	}
+	if len(err.fields) > 0 {
+		m["fields"] = err.Fields()
+	}
+	if err.build != "" {
+		m["build"] = err.build
+	}
+	if err.hint != "" {
+		m["hint"] = err.hint
+	}
+	return m
+}
@@ -0,0 +1,38 @@
+package errors
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScrubberAppliesToLogSinks(t *testing.T) {
+	defer func(s func(string) string) { MarshalScrubber = s }(MarshalScrubber)
+	MarshalScrubber = func(s string) string {
+		return strings.ReplaceAll(s, "alice@example.com", "[email]")
+	}
+	err := WrapPrefix(New("no user alice@example.com"), "lookup alice@example.com", 0)
+
+	m := err.ToMap()
+	if msg := m["message"].(string); strings.Contains(msg, "alice") {
+		t.Errorf("ToMap message not scrubbed: %q", msg)
+	}
+	if prefix := m["prefix"].(string); strings.Contains(prefix, "alice") {
+		t.Errorf("ToMap prefix not scrubbed: %q", prefix)
+	}
+	for _, field := range err.ZapFields() {
+		if s, ok := field.Value.(string); ok && strings.Contains(s, "alice") {
+			t.Errorf("ZapFields %s not scrubbed: %q", field.Key, s)
+		}
+	}
+}
+
+func TestScrubberAppliesToFilePaths(t *testing.T) {
+	defer func(s func(string) string) { MarshalScrubber = s }(MarshalScrubber)
+	MarshalScrubber = func(s string) string {
+		return strings.ReplaceAll(s, "json_test.go", "[file]")
+	}
+	stack := New("x").ToMap()["stack"].([]map[string]interface{})
+	if file := stack[0]["file"].(string); !strings.HasSuffix(file, "[file]") {
+		t.Errorf("ToMap file not scrubbed: %q", file)
+	}
+}
@@ -0,0 +1,51 @@
+package errors
+
+import (
+	"sync"
+	"time"
+)
+
+// LogLimiter limits how often the same error gets logged. Errors are the same
+// when they share a fingerprint, as used by GroupByFingerprint. It is safe for
+// concurrent use.
+//
+//	if limiter.Allow(err) {
+//		log.Print(err)
+//	}
+type LogLimiter struct {
+	rate   int
+	window time.Duration
+
+	mu     sync.Mutex
+	start  time.Time
+	counts map[string]int
+}
+
+// NewLogLimiter makes a LogLimiter allowing each error at most rate times per
+// window.
+func NewLogLimiter(rate int, window time.Duration) *LogLimiter {
+	return &LogLimiter{
+		rate:   rate,
+		window: window,
+		counts: make(map[string]int),
+	}
+}
+
+// Allow reports whether err may be logged, counting it if so. Counts start
+// over for every error once window has passed since the current one began.
+func (l *LogLimiter) Allow(err error) bool {
+	key := groupKey(err)
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if now.Sub(l.start) >= l.window {
+		l.start = now
+		l.counts = make(map[string]int)
+	}
+	if l.counts[key] >= l.rate {
+		return false
+	}
+	l.counts[key]++
+	return true
+}
@@ -0,0 +1,28 @@
+package errors
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLogLimiterConcurrentAllow(t *testing.T) {
+	sentinel := New("shared")
+	limiter := NewLogLimiter(3, time.Hour)
+	var allowed int32
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if limiter.Allow(sentinel) {
+				atomic.AddInt32(&allowed, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	if allowed != 3 {
+		t.Errorf("allowed %d times, want 3", allowed)
+	}
+}
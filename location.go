@@ -0,0 +1,39 @@
+package errors
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+var (
+	ownPackageOnce sync.Once
+	ownPackage     string
+)
+
+// isInternalFrame reports whether frame belongs to the runtime or to this
+// package, which are never where an error came from as far as users care.
+func isInternalFrame(frame StackFrame) bool {
+	ownPackageOnce.Do(func() {
+		ownPackage, _ = packageAndName(runtime.FuncForPC(reflect.ValueOf(New).Pointer()))
+	})
+	return frame.Package == "runtime" || strings.HasPrefix(frame.Package, "runtime/") || frame.Package == ownPackage
+}
+
+// ErrorWithLocation returns the message followed by the function, file and
+// line of the first frame outside of the runtime and this package, as in
+// "connection refused (at main.connect, conn.go:42)". Frames left out of
+// formatted stacks, e.g. by HideFunction, are skipped too. It returns the
+// plain message when there is no such frame.
+func (err *CommonError) ErrorWithLocation() string {
+	for _, frame := range err.visibleFrames() {
+		if !isInternalFrame(frame) {
+			return fmt.Sprintf("%s (at %s.%s, %s:%d)", err.Error(), path.Base(frame.Package), frame.Name, filepath.Base(frame.File), frame.LineNumber)
+		}
+	}
+	return err.Error()
+}
@@ -0,0 +1,25 @@
+package errors_test
+
+import (
+	stderrors "errors"
+	"strings"
+	"testing"
+
+	"github.com/monoculum/errors"
+)
+
+func wrapDBError(err error) *errors.CommonError {
+	return errors.Wrap(err, 0)
+}
+
+func queryUser() *errors.CommonError {
+	return wrapDBError(stderrors.New("connection refused"))
+}
+
+func TestErrorWithLocationSkipsHiddenFunctions(t *testing.T) {
+	errors.HideFunction("github.com/monoculum/errors_test.wrapDBError")
+	got := queryUser().ErrorWithLocation()
+	if !strings.HasPrefix(got, "connection refused (at errors_test.queryUser, location_test.go:") {
+		t.Errorf("ErrorWithLocation = %q, want the location in queryUser", got)
+	}
+}
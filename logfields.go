@@ -0,0 +1,39 @@
+package errors
+
+import "sort"
+
+// LogField is a key-value pair for structured loggers.
+type LogField struct {
+	Key   string
+	Value interface{}
+}
+
+// ZapFields returns the error as fields for structured loggers such as zap,
+// e.g. by converting each of them with zap.Any: the message, type, code,
+// status and severity when set, the attached fields and the stack. It needs
+// no logger dependency; a zerolog adapter is built with the zerolog build
+// tag. MarshalScrubber is applied to the message and the formatted stack.
+func (err *CommonError) ZapFields() []LogField {
+	fields := []LogField{
+		{Key: "error", Value: scrub(err.Error())},
+		{Key: "error_type", Value: err.TypeName()},
+	}
+	if err.code != "" {
+		fields = append(fields, LogField{Key: "error_code", Value: err.code})
+	}
+	if err.status != 0 {
+		fields = append(fields, LogField{Key: "error_status", Value: err.status})
+	}
+	if err.severity != SeverityUnset {
+		fields = append(fields, LogField{Key: "error_severity", Value: err.severity.String()})
+	}
+	keys := make([]string, 0, len(err.fields))
+	for k := range err.fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fields = append(fields, LogField{Key: k, Value: err.fields[k]})
+	}
+	return append(fields, LogField{Key: "error_stack", Value: scrub(string(err.Stack()))})
+}
@@ -0,0 +1,20 @@
+package errors
+
+// MarkLogged returns a copy of the error marked as logged, so that code
+// further up can tell with IsLogged and avoid logging it again. Like the
+// other annotations it returns a copy: pass the result on, as in
+// return err.MarkLogged().
+func (err *CommonError) MarkLogged() *CommonError {
+	c := err.clone()
+	c.logged = true
+	return c
+}
+
+// IsLogged reports whether any Error in the chain of err was marked with
+// MarkLogged.
+func IsLogged(err error) bool {
+	return walk(err, func(e error) bool {
+		c, ok := e.(*CommonError)
+		return ok && c != nil && c.logged
+	})
+}
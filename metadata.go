@@ -0,0 +1,146 @@
+package errors
+
+import "fmt"
+
+// The With methods below return an annotated copy of the error and leave the
+// error they are called on unchanged, so that package level sentinels can be
+// annotated safely.
+
+// WithCode returns a copy of the error with an application specific code,
+// e.g. "not_found".
+func (err *CommonError) WithCode(code string) *CommonError {
+	c := err.clone()
+	c.code = code
+	return c
+}
+
+// WithStatus returns a copy of the error with a status, usually the HTTP
+// status code that should be reported for it.
+func (err *CommonError) WithStatus(status int) *CommonError {
+	c := err.clone()
+	c.status = status
+	return c
+}
+
+// WithField returns a copy of the error with a key-value pair attached.
+// Setting an existing key replaces its value.
+func (err *CommonError) WithField(key string, value interface{}) *CommonError {
+	c := err.clone()
+	if c.fields == nil {
+		c.fields = make(map[string]interface{})
+	}
+	c.fields[key] = value
+	return c
+}
+
+// Code returns the code set with WithCode, or "" if none was set.
+func (err *CommonError) Code() string {
+	return err.code
+}
+
+// Status returns the status set with WithStatus, or 0 if none was set.
+func (err *CommonError) Status() int {
+	return err.status
+}
+
+// Fields returns a copy of the fields attached with WithField.
+func (err *CommonError) Fields() map[string]interface{} {
+	fields := make(map[string]interface{}, len(err.fields))
+	for k, v := range err.fields {
+		fields[k] = v
+	}
+	return fields
+}
+
+// Metadata walks the chain of err and returns the metadata attached to any
+// Error in it. code and status come from the outermost Error that set them;
+// fields are merged, outer values winning over inner ones for the same key.
+// Errors wrapping several errors, like the ones from errors.Join, are
+// walked member by member, depth first. ok is false when no metadata was
+// found at all.
+func Metadata(err error) (code string, status int, fields map[string]interface{}, ok bool) {
+	fields = make(map[string]interface{})
+	walk(err, func(e error) bool {
+		c, isCommon := e.(*CommonError)
+		if !isCommon || c == nil {
+			return false
+		}
+		if code == "" && c.code != "" {
+			code, ok = c.code, true
+		}
+		if status == 0 && c.status != 0 {
+			status, ok = c.status, true
+		}
+		for k, v := range c.fields {
+			if _, set := fields[k]; !set {
+				fields[k] = v
+				ok = true
+			}
+		}
+		return false
+	})
+	return code, status, fields, ok
+}
+
+// WithCause returns a copy of the error with a secondary cause attached,
+// without changing its message, e.g. a cleanup that also failed after the
+// operation itself did. The cause is shown in ErrorStack after a "caused by:"
+// line; Unwrap still returns the primary error.
+func (err *CommonError) WithCause(cause error) *CommonError {
+	c := err.clone()
+	c.cause = cause
+	return c
+}
+
+// Cause2 returns the secondary cause set with WithCause, or nil.
+func (err *CommonError) Cause2() error {
+	return err.cause
+}
+
+// AllCodes returns every code set on an Error in the chain of err, outermost
+// first, walking the members of joined errors depth first.
+func AllCodes(err error) []string {
+	codes := []string{}
+	walk(err, func(e error) bool {
+		if c, ok := e.(*CommonError); ok && c != nil && c.code != "" {
+			codes = append(codes, c.code)
+		}
+		return false
+	})
+	return codes
+}
+
+// AllStatuses returns every status set on an Error in the chain of err,
+// outermost first, walking the members of joined errors depth first.
+func AllStatuses(err error) []int {
+	statuses := []int{}
+	walk(err, func(e error) bool {
+		if c, ok := e.(*CommonError); ok && c != nil && c.status != 0 {
+			statuses = append(statuses, c.status)
+		}
+		return false
+	})
+	return statuses
+}
+
+// MatchCode walks the chain of err and returns the first code, outermost
+// first, that is one of codes. It reports false when none of them is set.
+func MatchCode(err error, codes ...string) (string, bool) {
+	for _, code := range AllCodes(err) {
+		for _, c := range codes {
+			if code == c {
+				return code, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Coded makes an Error with the given code and a message formatted as by
+// Errorf, with the stacktrace pointing at the caller of Coded.
+func Coded(code string, format string, a ...interface{}) *CommonError {
+	err := wrapSkip(fmt.Errorf(format, a...), 0)
+	err.source = SourceErrorf
+	err.code = code
+	return err
+}
@@ -0,0 +1,133 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"testing"
+)
+
+var errFoo = New("foo")
+
+func lookupUser() *CommonError {
+	return WrapPrefix(errFoo.WithField("user", 1).WithCode("user_missing").WithStatus(404), "lookup user", 0)
+}
+
+func lookupOrder() *CommonError {
+	return WrapPrefix(errFoo.WithField("order", 2).WithCode("order_missing").WithStatus(410), "lookup order", 0)
+}
+
+func TestAnnotatingSentinelLeavesItUnchanged(t *testing.T) {
+	user, order := lookupUser(), lookupOrder()
+
+	if msg := errFoo.Error(); msg != "foo" {
+		t.Errorf("sentinel message = %q, want %q", msg, "foo")
+	}
+	if fields := errFoo.Fields(); len(fields) != 0 {
+		t.Errorf("sentinel fields = %v, want none", fields)
+	}
+	if errFoo.Code() != "" || errFoo.Status() != 0 {
+		t.Errorf("sentinel code, status = %q, %d, want none", errFoo.Code(), errFoo.Status())
+	}
+	if fields := user.Fields(); len(fields) != 1 || fields["user"] != 1 {
+		t.Errorf("user fields = %v, want only user", fields)
+	}
+	if fields := order.Fields(); len(fields) != 1 || fields["order"] != 2 {
+		t.Errorf("order fields = %v, want only order", fields)
+	}
+	if user.Error() != "lookup user: foo" || order.Error() != "lookup order: foo" {
+		t.Errorf("messages = %q, %q", user.Error(), order.Error())
+	}
+}
+
+// loopError wraps inner, which is made to wrap the loopError again.
+type loopError struct {
+	inner error
+}
+
+func (e *loopError) Error() string { return "loop" }
+func (e *loopError) Unwrap() error { return e.inner }
+
+func TestChainHelpersStopOnCycles(t *testing.T) {
+	l := &loopError{}
+	err := Wrap(l, 0).WithCode("loop").WithStatus(500)
+	l.inner = err
+
+	if code, status, _, _ := Metadata(err); code != "loop" || status != 500 {
+		t.Errorf("Metadata = %q, %d, want %q, 500", code, status, "loop")
+	}
+	if codes := AllCodes(err); len(codes) != 1 {
+		t.Errorf("AllCodes = %v, want one code", codes)
+	}
+	if statuses := AllStatuses(err); len(statuses) != 1 {
+		t.Errorf("AllStatuses = %v, want one status", statuses)
+	}
+	if Key(err) == "" {
+		t.Error("Key is empty")
+	}
+	if groups := GroupByFingerprint([]error{err, l}); len(groups) != 1 {
+		t.Errorf("GroupByFingerprint made %d groups, want 1", len(groups))
+	}
+	var set ErrorSet
+	if !set.Add(err) || set.Add(l) {
+		t.Error("ErrorSet does not treat both ends of the cycle as the same error")
+	}
+}
+
+func TestChainHelpersSkipNilErrors(t *testing.T) {
+	var nilErr *CommonError
+	err := fmt.Errorf("outer: %w", nilErr)
+
+	if _, _, _, ok := Metadata(err); ok {
+		t.Error("Metadata found metadata on a nil *CommonError")
+	}
+	if codes := AllCodes(err); len(codes) != 0 {
+		t.Errorf("AllCodes = %v, want none", codes)
+	}
+	if Key(nilErr) != "" {
+		t.Errorf("Key(nil *CommonError) = %q, want empty", Key(nilErr))
+	}
+	var set ErrorSet
+	if set.Add(nilErr) {
+		t.Error("ErrorSet added a nil *CommonError")
+	}
+	set.Add(err)
+
+	if got := root(err); got != err {
+		t.Errorf("root = %v, want the error around the nil *CommonError", got)
+	}
+	if got := Wrap(err, 0).RootMessage(); got != err.Error() {
+		t.Errorf("RootMessage = %q, want %q", got, err.Error())
+	}
+	if RootIs(err, stderrors.New("other")) {
+		t.Error("RootIs matches an unrelated error")
+	}
+	if got := UnwrapTo(err, 3); got != error(nilErr) {
+		t.Errorf("UnwrapTo = %v, want the nil *CommonError", got)
+	}
+	if IsStrict(err, stderrors.New("other")) {
+		t.Error("IsStrict matches an unrelated error")
+	}
+	if Is(nilErr, stderrors.New("other")) || Is(stderrors.New("other"), nilErr) {
+		t.Error("Is matches a nil *CommonError with an unrelated error")
+	}
+	if stderrors.Is(err, New("other")) {
+		t.Error("errors.Is matches a nil *CommonError with an unrelated error")
+	}
+}
+
+func TestChainHelpersWalkJoinedErrors(t *testing.T) {
+	err := stderrors.Join(stderrors.New("plain"), New("x").WithCode("not_found").WithStatus(404))
+
+	if code, status, _, _ := Metadata(err); code != "not_found" || status != 404 {
+		t.Errorf("Metadata = %q, %d, want %q, 404", code, status, "not_found")
+	}
+	if codes := AllCodes(err); len(codes) != 1 || codes[0] != "not_found" {
+		t.Errorf("AllCodes = %v, want [not_found]", codes)
+	}
+	if statuses := AllStatuses(err); len(statuses) != 1 || statuses[0] != 404 {
+		t.Errorf("AllStatuses = %v, want [404]", statuses)
+	}
+	if !hasCommonError(err) {
+		t.Error("hasCommonError does not find the joined *CommonError")
+	}
+}
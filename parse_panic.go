@@ -63,7 +63,7 @@ func ParsePanic(text string) (*CommonError, error) {
 	}
 
 	if state == "done" || state == "parsing" {
-		return &CommonError{Err: uncaughtPanic{message}, frames: stack}, nil
+		return &CommonError{Err: uncaughtPanic{message}, frames: stack, build: buildInfo(), source: SourcePanic}, nil
 	}
 	return nil, Errorf("could not parse panic: %v", text)
 }
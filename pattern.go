@@ -0,0 +1,18 @@
+package errors
+
+import "regexp"
+
+// MatchPattern reports whether the message of any error in the chain of err
+// matches pattern. It is a last resort for classifying third party errors
+// that offer no sentinel, type or code to match: messages are not part of
+// an API, they change between versions and may be localized, and outer
+// messages include the inner ones, so patterns can match at several levels.
+// Prefer Is, ContainsType or MatchCode whenever possible.
+func MatchPattern(err error, pattern *regexp.Regexp) bool {
+	if pattern == nil {
+		return false
+	}
+	return walk(err, func(e error) bool {
+		return !IsNil(e) && pattern.MatchString(e.Error())
+	})
+}
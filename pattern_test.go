@@ -0,0 +1,18 @@
+package errors
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+)
+
+func TestMatchPatternNilCommonError(t *testing.T) {
+	var nilErr *CommonError
+	err := fmt.Errorf("dial tcp: %w", nilErr)
+	if !MatchPattern(err, regexp.MustCompile(`^dial tcp`)) {
+		t.Error("MatchPattern does not match the outer message")
+	}
+	if MatchPattern(err, regexp.MustCompile(`refused`)) {
+		t.Error("MatchPattern matches a message that is not in the chain")
+	}
+}
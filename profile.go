@@ -0,0 +1,30 @@
+package errors
+
+import (
+	"context"
+	"runtime/pprof"
+)
+
+// WithProfileLabels returns a copy of the error with the pprof labels of ctx,
+// as set by pprof.Do or pprof.WithLabels, linking the error to the
+// profiling samples taken in the same context. Labels are only recorded by
+// calling it, so errors made outside labelled code pay nothing.
+func (err *CommonError) WithProfileLabels(ctx context.Context) *CommonError {
+	c := err.clone()
+	c.labels = make(map[string]string)
+	pprof.ForLabels(ctx, func(key, value string) bool {
+		c.labels[key] = value
+		return true
+	})
+	return c
+}
+
+// ProfileLabels returns a copy of the pprof labels recorded by
+// WithProfileLabels.
+func (err *CommonError) ProfileLabels() map[string]string {
+	labels := make(map[string]string, len(err.labels))
+	for k, v := range err.labels {
+		labels[k] = v
+	}
+	return labels
+}
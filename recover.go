@@ -1,9 +1,104 @@
 package errors
 
-import "runtime"
+import (
+	"fmt"
+	"runtime"
+)
 
 func Recover() []byte {
-	stack := make([]byte, 1<<16)
-	length := runtime.Stack(stack, false)
-	return stack[:length]
+	return RecoverInto(make([]byte, 1<<16))
+}
+
+// RecoverInto is like Recover but writes the stack into buf, e.g. one taken
+// from a sync.Pool, and returns the part of buf that was used. The stack is
+// cut off when it does not fit, so buf trades memory kept around against how
+// much of deep stacks survives; Recover uses 64KiB.
+func RecoverInto(buf []byte) []byte {
+	length := runtime.Stack(buf, false)
+	return buf[:length]
+}
+
+// WrapPanic makes an Error from a value returned by recover(). The message is
+// the value formatted with fmt's %v, TypeName reports "panic" and the value
+// itself is kept untouched and available from PanicValue. When called from
+// the deferred function the stacktrace includes the frames that panicked. The
+// skip parameter indicates how far up the stack to start the stacktrace.
+// 0 is from the current call, 1 from its caller, etc.
+func WrapPanic(v interface{}, skip int) *CommonError {
+	err := newError(uncaughtPanic{fmt.Sprintf("%v", v)}, skip, SourcePanic)
+	err.panicValue = v
+	return err
+}
+
+// PanicValue returns the original value the error was recovered from, or nil
+// if the error did not come from WrapPanic.
+func (err *CommonError) PanicValue() interface{} {
+	return err.panicValue
+}
+
+// RecoverOnlyErrors turns a recovered error into an Error and panics again
+// with any other value, so that expected error panics are handled while
+// programming bugs stay loud. It returns nil when r is nil. recover() only
+// works when called by the deferred function itself, so the recovered value
+// is passed in:
+//
+//	defer func() {
+//		if err := errors.RecoverOnlyErrors(recover()); err != nil {
+//			...
+//		}
+//	}()
+//
+// A *CommonError, e.g. from MustWrap, is returned as is with its stack.
+func RecoverOnlyErrors(r interface{}) *CommonError {
+	switch r := r.(type) {
+	case nil:
+		return nil
+	case *CommonError:
+		return r
+	case error:
+		return WrapPanic(r, 1)
+	default:
+		panic(r)
+	}
+}
+
+// Guard calls fn and returns its error. If fn panics the panic is recovered
+// and returned as an Error made by WrapPanic, whose stack shows where the
+// panic happened; a panic with a *CommonError, e.g. from MustWrap, returns
+// that error.
+//
+//	err := errors.Guard(func() error { return risky() })
+func Guard(fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if e, ok := r.(*CommonError); ok {
+				err = e
+			} else {
+				err = WrapPanic(r, 0)
+			}
+		}
+	}()
+	return fn()
+}
+
+// IsPanic reports whether err came from a recovered panic, made by
+// WrapPanic or ParsePanic, anywhere in its chain.
+func IsPanic(err error) bool {
+	return walk(err, func(e error) bool {
+		_, ok := e.(uncaughtPanic)
+		return ok
+	})
+}
+
+// SafeForEach calls fn on every item in order, recovering panics like Guard
+// so that one failing item does not stop the others. The result has one
+// entry per item, at the same index: nil for items that succeeded, the
+// returned or recovered error for the others.
+func SafeForEach[T any](items []T, fn func(T) error) []error {
+	errs := make([]error, len(items))
+	for i, item := range items {
+		item := item
+		errs[i] = Guard(func() error { return fn(item) })
+	}
+	return errs
 }
@@ -0,0 +1,124 @@
+package errors
+
+import (
+	"sync"
+	"time"
+)
+
+// Reporter buffers errors and hands them in batches to a sink, e.g. a client
+// for an error tracking service. A batch is sent once it holds size errors,
+// once interval has passed since its first error, or when Flush is called.
+// Errors with the same fingerprint as one already in the batch, as used by
+// GroupByFingerprint, are dropped. It is safe for concurrent use, and the
+// sink is never called concurrently.
+//
+//	r := errors.NewReporter(client.Send, 100, 5*time.Second)
+//	defer r.Flush()
+//	...
+//	r.Report(err)
+type Reporter struct {
+	sink     func([]*CommonError) error
+	size     int
+	interval time.Duration
+
+	sendMu sync.Mutex
+
+	mu    sync.Mutex
+	batch []*CommonError
+	seen  map[string]bool
+	timer *time.Timer
+	err   error
+}
+
+// NewReporter makes a Reporter sending batches of at most size errors to sink
+// at least every interval. A size or interval of 0 turns the respective limit
+// off, leaving a batch to the other limit or to Flush.
+func NewReporter(sink func([]*CommonError) error, size int, interval time.Duration) *Reporter {
+	return &Reporter{
+		sink:     sink,
+		size:     size,
+		interval: interval,
+		seen:     make(map[string]bool),
+	}
+}
+
+// Report adds err to the current batch, sending the batch right away if that
+// makes it full. Errors that are not *CommonError are wrapped with the stack
+// of the caller of Report. Report(nil) does nothing.
+func (r *Reporter) Report(err error) {
+	if IsNil(err) {
+		return
+	}
+	e, ok := err.(*CommonError)
+	if !ok {
+		e = wrapSkip(err, 0)
+	}
+	key := groupKey(err)
+
+	r.mu.Lock()
+	if r.seen[key] {
+		r.mu.Unlock()
+		return
+	}
+	r.seen[key] = true
+	r.batch = append(r.batch, e)
+	full := r.size > 0 && len(r.batch) >= r.size
+	if !full && r.timer == nil && r.interval > 0 {
+		r.timer = time.AfterFunc(r.interval, func() { r.keep(r.send()) })
+	}
+	r.mu.Unlock()
+
+	if full {
+		r.keep(r.send())
+	}
+}
+
+// Flush sends the current batch, if any, and returns the error of the sink.
+// When the sink failed on a batch sent by Report or by the interval timer
+// since the last Flush, and this batch went through, that earlier error is
+// returned instead.
+func (r *Reporter) Flush() error {
+	err := r.send()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err == nil {
+		err = r.err
+	}
+	r.err = nil
+	return err
+}
+
+// send hands the current batch to the sink and starts a new one.
+func (r *Reporter) send() error {
+	r.sendMu.Lock()
+	defer r.sendMu.Unlock()
+
+	r.mu.Lock()
+	batch := r.batch
+	r.batch = nil
+	r.seen = make(map[string]bool)
+	if r.timer != nil {
+		r.timer.Stop()
+		r.timer = nil
+	}
+	r.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+	return r.sink(batch)
+}
+
+// keep records the first error of a batch sent outside of Flush, for the next
+// Flush to return.
+func (r *Reporter) keep(err error) {
+	if err == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err == nil {
+		r.err = err
+	}
+}
@@ -0,0 +1,34 @@
+package errors
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestReporterConcurrentReport(t *testing.T) {
+	sentinel := New("shared")
+	var mu sync.Mutex
+	var sent []*CommonError
+	r := NewReporter(func(batch []*CommonError) error {
+		mu.Lock()
+		defer mu.Unlock()
+		sent = append(sent, batch...)
+		return nil
+	}, 100, 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.Report(sentinel)
+		}()
+	}
+	wg.Wait()
+	if err := r.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 1 || sent[0] != sentinel {
+		t.Errorf("sent %v, want the sentinel once", sent)
+	}
+}
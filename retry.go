@@ -0,0 +1,30 @@
+package errors
+
+import "time"
+
+// Retry calls fn until it succeeds, up to attempts times, sleeping backoff
+// between calls. Only errors that IsTimeout, or that have a Temporary method
+// returning true somewhere in their chain, are retried; others are returned
+// right away. The last error is returned wrapped like Wrap, with the number
+// of calls made in the "attempts" field.
+func Retry(attempts int, backoff time.Duration, fn func() error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if attempt >= attempts || !(IsTimeout(err) || isTemporary(err)) {
+			return wrapSkip(err, 0).WithField("attempts", attempt)
+		}
+		time.Sleep(backoff)
+	}
+}
+
+// isTemporary reports whether any error in the chain of err has a Temporary
+// method returning true.
+func isTemporary(err error) bool {
+	return walk(err, func(e error) bool {
+		t, ok := e.(interface{ Temporary() bool })
+		return ok && t.Temporary()
+	})
+}
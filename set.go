@@ -0,0 +1,61 @@
+package errors
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ErrorSet collects distinct errors, in the order they were first added.
+// Errors with a *CommonError in their chain are the same when they share a
+// Fingerprint; other errors when they have the same type and message. It is
+// safe for concurrent use; the zero value is an empty set.
+type ErrorSet struct {
+	mu   sync.Mutex
+	keys map[string]bool
+	errs []error
+}
+
+// Add adds err to the set unless an equal error is already in it and reports
+// whether it was added. nil errors are never added.
+func (s *ErrorSet) Add(err error) bool {
+	if IsNil(err) {
+		return false
+	}
+	key := groupKey(err)
+	if !hasCommonError(err) {
+		key = fmt.Sprintf("%T: %s", err, key)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.keys[key] {
+		return false
+	}
+	if s.keys == nil {
+		s.keys = make(map[string]bool)
+	}
+	s.keys[key] = true
+	s.errs = append(s.errs, err)
+	return true
+}
+
+// Len returns the number of errors in the set.
+func (s *ErrorSet) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.errs)
+}
+
+// Slice returns the errors in the set in insertion order.
+func (s *ErrorSet) Slice() []error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]error(nil), s.errs...)
+}
+
+func hasCommonError(err error) bool {
+	return walk(err, func(e error) bool {
+		c, ok := e.(*CommonError)
+		return ok && c != nil
+	})
+}
@@ -0,0 +1,23 @@
+package errors
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestErrorSetConcurrentAdd(t *testing.T) {
+	sentinel := New("shared")
+	var set ErrorSet
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			set.Add(sentinel)
+		}()
+	}
+	wg.Wait()
+	if set.Len() != 1 {
+		t.Errorf("Len = %d, want 1", set.Len())
+	}
+}
@@ -0,0 +1,79 @@
+package errors
+
+// Severity ranks how serious an error is. The zero value means no severity
+// was set.
+type Severity int
+
+// The severities, from least to most serious.
+const (
+	SeverityUnset Severity = iota
+	SeverityInfo
+	SeverityWarning
+	SeverityError
+	SeverityFatal
+)
+
+var severityNames = [...]string{
+	SeverityUnset:   "",
+	SeverityInfo:    "info",
+	SeverityWarning: "warning",
+	SeverityError:   "error",
+	SeverityFatal:   "fatal",
+}
+
+func (s Severity) String() string {
+	if s < 0 || int(s) >= len(severityNames) {
+		return "unknown"
+	}
+	return severityNames[s]
+}
+
+// StackSeverityThreshold is the severity below which NewSeverity does not
+// capture a stack, so that expected low-severity errors cost no more than a
+// plain error. The default, SeverityUnset, captures a stack for everything.
+var StackSeverityThreshold = SeverityUnset
+
+// NewSeverity makes an Error from the given value like New, with the given
+// severity. When severity is below StackSeverityThreshold no stack is
+// captured at all: HasFrames reports false and ErrorStack says
+// "(no stack available)". Errors made by other constructors always have a
+// stack, even when WithSeverity lowers their severity later.
+func NewSeverity(severity Severity, e interface{}) *CommonError {
+	if severity < StackSeverityThreshold {
+		return &CommonError{
+			Err:      toError(e),
+			build:    buildInfo(),
+			source:   SourceNew,
+			severity: severity,
+		}
+	}
+	err := newError(toError(e), 0, SourceNew)
+	err.severity = severity
+	return err
+}
+
+// WithSeverity returns a copy of the error with the given severity.
+func (err *CommonError) WithSeverity(severity Severity) *CommonError {
+	c := err.clone()
+	c.severity = severity
+	return c
+}
+
+// Severity returns the severity of the error, SeverityUnset if none was set.
+func (err *CommonError) Severity() Severity {
+	return err.severity
+}
+
+// SeverityOf returns the highest severity set on any Error in the chain of
+// err, so that an inner warning wrapped at a fatal site is fatal. It returns
+// SeverityUnset when no severity is set.
+func SeverityOf(err error) Severity {
+	highest := SeverityUnset
+	walk(err, func(e error) bool {
+		if e, ok := e.(*CommonError); ok && e != nil && e.severity > highest {
+			highest = e.severity
+		}
+		return false
+	})
+	return highest
+}
@@ -0,0 +1,22 @@
+package errors
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSeverityOfEscalates(t *testing.T) {
+	inner := NewSeverity(SeverityInfo, "cache miss")
+	outer := Relayer(fmt.Errorf("load config: %w", inner), 0).WithSeverity(SeverityFatal)
+
+	if got := SeverityOf(outer); got != SeverityFatal {
+		t.Errorf("SeverityOf = %v, want %v", got, SeverityFatal)
+	}
+	if got := SeverityOf(inner); got != SeverityInfo {
+		t.Errorf("SeverityOf(inner) = %v, want %v", got, SeverityInfo)
+	}
+	lowered := Relayer(outer, 0).WithSeverity(SeverityWarning)
+	if got := SeverityOf(lowered); got != SeverityFatal {
+		t.Errorf("SeverityOf under a warning = %v, want %v", got, SeverityFatal)
+	}
+}
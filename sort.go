@@ -0,0 +1,21 @@
+package errors
+
+// ErrorSlice attaches the methods of sort.Interface to []error, sorting by
+// the rendered Error() message, prefixes included. nil errors sort first.
+// It gives deterministic output for errors that were collected in no
+// particular order, e.g. from concurrent workers:
+//
+//	sort.Sort(errors.ErrorSlice(errs))
+type ErrorSlice []error
+
+func (s ErrorSlice) Len() int           { return len(s) }
+func (s ErrorSlice) Less(i, j int) bool { return message(s[i]) < message(s[j]) }
+func (s ErrorSlice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// message returns err.Error(), or "" for a nil error.
+func message(err error) string {
+	if IsNil(err) {
+		return ""
+	}
+	return err.Error()
+}
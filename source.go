@@ -0,0 +1,30 @@
+package errors
+
+// StackSource tells how the stack of an error was captured.
+type StackSource int
+
+// The ways an error's stack can be captured, as reported by Source.
+const (
+	SourceUnknown StackSource = iota
+	SourceNew
+	SourceWrap
+	SourceErrorf
+	SourcePanic
+	SourceManual
+)
+
+var sourceNames = [...]string{
+	SourceUnknown: "unknown",
+	SourceNew:     "new",
+	SourceWrap:    "wrap",
+	SourceErrorf:  "errorf",
+	SourcePanic:   "panic",
+	SourceManual:  "manual",
+}
+
+func (s StackSource) String() string {
+	if s < 0 || int(s) >= len(sourceNames) {
+		return "unknown"
+	}
+	return sourceNames[s]
+}
@@ -0,0 +1,33 @@
+package errors
+
+import "bytes"
+
+// SpanStackDepth is the number of top stackframes RecordOnSpan attaches to a
+// span.
+var SpanStackDepth = 10
+
+// SpanRecorder is the part of a tracing span, such as an OpenTelemetry
+// trace.Span, that RecordOnSpan needs. Adapting a real span only takes
+// converting the attributes to the tracer's own attribute type, which keeps
+// this package free of tracing dependencies.
+type SpanRecorder interface {
+	RecordError(err error, attributes map[string]string)
+}
+
+// RecordOnSpan records the error on the given span. The attributes follow the
+// OpenTelemetry exception conventions: exception.message, exception.type and
+// exception.stacktrace, the latter holding the top SpanStackDepth frames.
+func (err *CommonError) RecordOnSpan(span SpanRecorder) {
+	var buf bytes.Buffer
+	for i, frame := range err.visibleFrames() {
+		if i == SpanStackDepth {
+			break
+		}
+		buf.WriteString(frame.String())
+	}
+	span.RecordError(err, map[string]string{
+		"exception.message":    err.Error(),
+		"exception.type":       err.TypeName(),
+		"exception.stacktrace": buf.String(),
+	})
+}
@@ -0,0 +1,36 @@
+package errors
+
+import (
+	"strings"
+	"testing"
+)
+
+// fakeSpan records what RecordOnSpan hands to a span, the way an adapter
+// around a real tracing span would.
+type fakeSpan struct {
+	err        error
+	attributes map[string]string
+}
+
+func (s *fakeSpan) RecordError(err error, attributes map[string]string) {
+	s.err, s.attributes = err, attributes
+}
+
+func TestRecordOnSpan(t *testing.T) {
+	err := New("connection refused")
+	span := &fakeSpan{}
+	err.RecordOnSpan(span)
+
+	if span.err != err {
+		t.Errorf("recorded error = %v, want %v", span.err, err)
+	}
+	if got := span.attributes["exception.message"]; got != "connection refused" {
+		t.Errorf("exception.message = %q, want %q", got, "connection refused")
+	}
+	if got := span.attributes["exception.type"]; got != "*errors.errorString" {
+		t.Errorf("exception.type = %q, want %q", got, "*errors.errorString")
+	}
+	if got := span.attributes["exception.stacktrace"]; !strings.Contains(got, "TestRecordOnSpan") {
+		t.Errorf("exception.stacktrace does not mention the test:\n%s", got)
+	}
+}
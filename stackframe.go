@@ -39,6 +39,43 @@ func NewStackFrame(pc uintptr) (frame StackFrame) {
 
 }
 
+// CurrentStack returns the frames of the current call stack, without making
+// an error. The skip parameter indicates how far up the stack to start.
+// 0 is from the caller of CurrentStack, 1 from its caller, etc. At most
+// MaxStackDepth frames are returned.
+func CurrentStack(skip int) []StackFrame {
+	stack, _ := callers(skip)
+	return resolveFrames(stack)
+}
+
+// resolveFrames turns program counters as returned by runtime.Callers into
+// stack frames. Unlike calling NewStackFrame on each of them, it uses
+// runtime.CallersFrames so that inlined calls, which share a program counter
+// with their caller, show up as frames of their own. ProgramCounter keeps
+// the program counter from stack, as NewStackFrame expects it.
+func resolveFrames(stack []uintptr) []StackFrame {
+	frames := make([]StackFrame, 0, len(stack))
+	for _, pc := range stack {
+		callersFrames := runtime.CallersFrames([]uintptr{pc})
+		for {
+			f, more := callersFrames.Next()
+			if f.PC != 0 || f.Function != "" {
+				frame := StackFrame{
+					File:           f.File,
+					LineNumber:     f.Line,
+					ProgramCounter: pc,
+				}
+				frame.Package, frame.Name = splitFuncName(f.Function)
+				frames = append(frames, frame)
+			}
+			if !more {
+				break
+			}
+		}
+	}
+	return frames
+}
+
 // Func returns the function that contained this frame.
 func (frame *StackFrame) Func() *runtime.Func {
 	if frame.ProgramCounter == 0 {
@@ -58,6 +95,12 @@ func (frame *StackFrame) String() string {
 	return str + fmt.Sprintf("\t%s: %s\n", frame.Name, source)
 }
 
+// Compact returns the stackframe on a single line as "function file:line",
+// which is easier to grep than the format of String.
+func (frame *StackFrame) Compact() string {
+	return fmt.Sprintf("%s %s:%d", frame.Name, frame.File, frame.LineNumber)
+}
+
 // SourceLine gets the line of code (from File and Line) of the original source if possible.
 func (frame *StackFrame) SourceLine() (string, error) {
 	data, err := ioutil.ReadFile(frame.File)
@@ -73,7 +116,10 @@ func (frame *StackFrame) SourceLine() (string, error) {
 }
 
 func packageAndName(fn *runtime.Func) (string, string) {
-	name := fn.Name()
+	return splitFuncName(fn.Name())
+}
+
+func splitFuncName(name string) (string, string) {
 	pkg := ""
 	// The name includes the path name to the package, which is unnecessary
 	// since the file name is already included.  Plus, it has center dots.
@@ -0,0 +1,28 @@
+package errors
+
+import "testing"
+
+// newInlined is small enough for the compiler to inline into its callers.
+func newInlined() *CommonError {
+	return New("inlined")
+}
+
+func TestStackFramesIncludeInlinedCalls(t *testing.T) {
+	frames := newInlined().StackFrames()
+	if len(frames) < 2 || frames[0].Name != "newInlined" || frames[1].Name != "TestStackFramesIncludeInlinedCalls" {
+		t.Fatalf("frames start with %v, want newInlined called from the test", frames[:2])
+	}
+}
+
+func TestStackFrameProgramCounter(t *testing.T) {
+	err := New("x")
+	frame := err.StackFrames()[0]
+	if frame.ProgramCounter != err.stack[0] {
+		t.Errorf("ProgramCounter = %#x, want the captured %#x", frame.ProgramCounter, err.stack[0])
+	}
+	again := NewStackFrame(frame.ProgramCounter)
+	if again.File != frame.File || again.LineNumber != frame.LineNumber || again.Name != frame.Name {
+		t.Errorf("NewStackFrame(ProgramCounter) = %s:%d %s, want %s:%d %s",
+			again.File, again.LineNumber, again.Name, frame.File, frame.LineNumber, frame.Name)
+	}
+}
@@ -0,0 +1,48 @@
+package errors
+
+// WithTags returns a copy of the error with the given tags added, e.g. "db"
+// or "transient", for filtering and metrics. Tags already on the error are
+// not added twice.
+func (err *CommonError) WithTags(tags ...string) *CommonError {
+	c := err.clone()
+	for _, tag := range tags {
+		if !containsString(c.tags, tag) {
+			c.tags = append(c.tags, tag)
+		}
+	}
+	return c
+}
+
+// Tags returns the tags of the error and of the Errors wrapped inside it,
+// outermost first and without duplicates.
+func (err *CommonError) Tags() []string {
+	var tags []string
+	walk(err, func(e error) bool {
+		if e, ok := e.(*CommonError); ok && e != nil {
+			for _, tag := range e.tags {
+				if !containsString(tags, tag) {
+					tags = append(tags, tag)
+				}
+			}
+		}
+		return false
+	})
+	return tags
+}
+
+// HasTag reports whether any Error in the chain of err has the given tag.
+func HasTag(err error, tag string) bool {
+	return walk(err, func(e error) bool {
+		c, ok := e.(*CommonError)
+		return ok && c != nil && containsString(c.tags, tag)
+	})
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package errors
+
+// WithTimeout wraps e like Wrap and marks whether it is a timeout, overriding
+// whatever the errors inside it report.
+func WithTimeout(e interface{}, timeout bool) *CommonError {
+	err := wrapSkip(e, 0).clone()
+	err.timeout, err.timeoutSet = timeout, true
+	return err
+}
+
+// Timeout reports whether the error is a timeout: the value given to
+// WithTimeout if it was used, IsTimeout of the wrapped error otherwise. It
+// makes Errors look like net.Error timeouts to code that checks for them.
+func (err *CommonError) Timeout() bool {
+	if err.timeoutSet {
+		return err.timeout
+	}
+	return IsTimeout(err.Err)
+}
+
+// IsTimeout reports whether any error in the chain of err has a Timeout
+// method returning true, like net.Error implementations do. An Error marked
+// with WithTimeout decides for everything it wraps.
+func IsTimeout(err error) bool {
+	timeout := false
+	walk(err, func(e error) bool {
+		if c, ok := e.(*CommonError); ok {
+			if c != nil && c.timeoutSet {
+				timeout = c.timeout
+				return true
+			}
+			return false
+		}
+		if t, ok := e.(interface{ Timeout() bool }); ok && t.Timeout() {
+			timeout = true
+			return true
+		}
+		return false
+	})
+	return timeout
+}
@@ -0,0 +1,54 @@
+package errors
+
+import stderrors "errors"
+
+// DefaultUserMessage is what UserMessageOf returns for errors without a user
+// message.
+var DefaultUserMessage = "An internal error occurred."
+
+// WithUserMessage wraps e like Wrap and sets msg as the message that is safe
+// to show to end users, while Error() keeps the internal details for logs.
+func WithUserMessage(e interface{}, msg string) *CommonError {
+	err := wrapSkip(e, 0).clone()
+	err.userMessage = msg
+	return err
+}
+
+// UserMessage returns the message set by WithUserMessage, or "".
+func (err *CommonError) UserMessage() string {
+	return err.userMessage
+}
+
+// UserMessageOf returns the first user message set on an Error in the chain
+// of err, outermost first, or DefaultUserMessage if there is none.
+func UserMessageOf(err error) string {
+	if msg, ok := userMessageOf(err); ok {
+		return msg
+	}
+	return DefaultUserMessage
+}
+
+func userMessageOf(err error) (msg string, ok bool) {
+	ok = walk(err, func(e error) bool {
+		if e, isCommon := e.(*CommonError); isCommon && e != nil && e.userMessage != "" {
+			msg = e.userMessage
+			return true
+		}
+		return false
+	})
+	return msg, ok
+}
+
+// Sanitize returns a plain error, as from the standard library's errors.New,
+// for returning across a public boundary without leaking stacks, fields or
+// types. Its message is the user message of err when one is set in the
+// chain, and err.Error() otherwise. Sanitize(nil) is nil.
+func Sanitize(err error) error {
+	if IsNil(err) {
+		return nil
+	}
+	if msg, ok := userMessageOf(err); ok {
+		return stderrors.New(msg)
+	}
+	return stderrors.New(err.Error())
+}
@@ -0,0 +1,116 @@
+package errors
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// A FieldError is a validation failure of a single field, addressed by its
+// path, e.g. "user.email".
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// Error returns the field path and the message.
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// FieldErrors collects the validation failures of a form or request. It
+// implements the Error interface, with the stacktrace pointing at the call to
+// NewFieldErrors, and marshals to JSON as a field->message object for API
+// responses. The zero value is an empty FieldErrors without a stack.
+type FieldErrors struct {
+	errs  []FieldError
+	trace *CommonError
+}
+
+// NewFieldErrors makes an empty FieldErrors.
+func NewFieldErrors() *FieldErrors {
+	return &FieldErrors{trace: newError(nil, 0, SourceNew)}
+}
+
+// Add records a failure of the given field.
+func (e *FieldErrors) Add(field, message string) *FieldErrors {
+	e.errs = append(e.errs, FieldError{Field: field, Message: message})
+	return e
+}
+
+// Len returns the number of failures recorded.
+func (e *FieldErrors) Len() int {
+	return len(e.errs)
+}
+
+// Errors returns the failures in the order they were added.
+func (e *FieldErrors) Errors() []FieldError {
+	return append([]FieldError(nil), e.errs...)
+}
+
+// Map returns the failures by field path. Several messages for the same
+// field are joined with "; ".
+func (e *FieldErrors) Map() map[string]string {
+	m := make(map[string]string, len(e.errs))
+	for _, fe := range e.errs {
+		if msg, ok := m[fe.Field]; ok {
+			m[fe.Field] = msg + "; " + fe.Message
+		} else {
+			m[fe.Field] = fe.Message
+		}
+	}
+	return m
+}
+
+// Error returns every failure as "field: message", joined with "; ".
+func (e *FieldErrors) Error() string {
+	msgs := make([]string, len(e.errs))
+	for i, fe := range e.errs {
+		msgs[i] = fe.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the failures as errors, so that the standard library's
+// errors.As can find a FieldError.
+func (e *FieldErrors) Unwrap() []error {
+	errs := make([]error, len(e.errs))
+	for i, fe := range e.errs {
+		errs[i] = fe
+	}
+	return errs
+}
+
+// Stack returns the callstack formatted the same way that go does
+// in runtime/debug.Stack()
+func (e *FieldErrors) Stack() []byte {
+	if e.trace == nil {
+		return nil
+	}
+	return e.trace.Stack()
+}
+
+// ErrorStack returns a string that contains both the
+// error message and the callstack.
+func (e *FieldErrors) ErrorStack() string {
+	return e.Error() + "\n" + string(e.Stack())
+}
+
+// StackFrames returns an array of frames containing information about the
+// stack.
+func (e *FieldErrors) StackFrames() []StackFrame {
+	if e.trace == nil {
+		return nil
+	}
+	return e.trace.StackFrames()
+}
+
+// TypeName returns the type this error, *errors.FieldErrors.
+func (e *FieldErrors) TypeName() string {
+	return reflect.TypeOf(e).String()
+}
+
+// MarshalJSON implements json.Marshaler, encoding the result of Map.
+func (e *FieldErrors) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.Map())
+}
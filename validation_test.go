@@ -0,0 +1,22 @@
+package errors
+
+import "testing"
+
+func TestFieldErrorsZeroValue(t *testing.T) {
+	var fe FieldErrors
+	fe.Add("email", "is required")
+
+	s, ok := AsError(&fe)
+	if !ok {
+		t.Fatal("AsError does not find the FieldErrors")
+	}
+	if frames := s.StackFrames(); len(frames) != 0 {
+		t.Errorf("StackFrames = %v, want none", frames)
+	}
+	if stack := s.Stack(); len(stack) != 0 {
+		t.Errorf("Stack = %q, want empty", stack)
+	}
+	if got, want := s.ErrorStack(), "email: is required\n"; got != want {
+		t.Errorf("ErrorStack = %q, want %q", got, want)
+	}
+}
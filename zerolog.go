@@ -0,0 +1,13 @@
+//go:build zerolog
+
+package errors
+
+import "github.com/rs/zerolog"
+
+// MarshalZerologObject implements zerolog.LogObjectMarshaler with the same
+// fields as ZapFields.
+func (err *CommonError) MarshalZerologObject(e *zerolog.Event) {
+	for _, field := range err.ZapFields() {
+		e.Interface(field.Key, field.Value)
+	}
+}